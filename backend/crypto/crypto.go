@@ -0,0 +1,38 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+
+	"github.com/emmansun/gmsm/sm3"
+)
+
+// 证书内容哈希算法，与链码记录在证书上的算法标识一致
+const (
+	HashSHA256 = "SHA-256"
+	HashSM3    = "SM3" // GM/T 0004
+)
+
+// hashAlgorithms 支持的证书内容哈希算法
+var hashAlgorithms = map[string]func() hash.Hash{
+	HashSHA256: sha256.New,
+	HashSM3:    sm3.New,
+}
+
+// Digest 使用指定算法计算摘要，未指定算法时为SHA-256（与早期证书一致）。
+// 检验员签名的校验由链码在签发时完成
+func Digest(algorithm string, data []byte) ([]byte, error) {
+	if algorithm == "" {
+		algorithm = HashSHA256
+	}
+
+	newHash, ok := hashAlgorithms[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported hash algorithm %q", algorithm)
+	}
+
+	h := newHash()
+	h.Write(data)
+	return h.Sum(nil), nil
+}
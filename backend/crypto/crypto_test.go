@@ -0,0 +1,32 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// 标准测试向量：FIPS 180-2和GB/T 32905-2016附录A对"abc"的摘要
+func TestDigest(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		want      string
+	}{
+		{HashSHA256, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{HashSM3, "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0"},
+		{"", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+	}
+
+	for _, tt := range tests {
+		digest, err := Digest(tt.algorithm, []byte("abc"))
+		if err != nil {
+			t.Fatalf("Digest(%q): %v", tt.algorithm, err)
+		}
+		if got := hex.EncodeToString(digest); got != tt.want {
+			t.Errorf("Digest(%q) = %s, want %s", tt.algorithm, got, tt.want)
+		}
+	}
+
+	if _, err := Digest("MD5", []byte("abc")); err == nil {
+		t.Error("Digest(MD5) succeeded, want unsupported algorithm error")
+	}
+}
@@ -1,96 +1,216 @@
 package fabric
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
 	"crypto/x509"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/hyperledger/fabric-gateway/pkg/client"
 	"github.com/hyperledger/fabric-gateway/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 )
 
+var (
+	// ErrPermissionDenied 链码拒绝了调用者的请求（MSP或角色属性不满足）
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrAlreadyExists 证书或证书编号已存在
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrUnauthenticated 访问令牌缺失或不属于钱包中的任何用户
+	ErrUnauthenticated = errors.New("unauthenticated")
+)
+
+// TransactionError 交易失败错误，包含背书节点返回的链码错误信息
+type TransactionError struct {
+	message string
+	kind    error
+}
+
+func (e *TransactionError) Error() string {
+	return e.message
+}
+
+// Unwrap 返回错误类别，便于使用errors.Is判断
+func (e *TransactionError) Unwrap() error {
+	return e.kind
+}
+
+// FabricClient 与peer的连接及钱包中各用户的网关，交易以调用者本人的身份签名
 type FabricClient struct {
-	gateway    *client.Gateway
-	network    *client.Network
-	contract   *client.Contract
 	connection *grpc.ClientConn
+	users      []WalletUser
+
+	mu        sync.Mutex
+	contracts map[string]*Contract // 按用户名缓存的合约，首次使用时连接网关
+}
+
+// Contract 以某一用户身份调用的链码合约
+type Contract struct {
+	gateway  *client.Gateway
+	contract *client.Contract
 }
 
 func NewFabricClient() (*FabricClient, error) {
-	// 证书和密钥路径（需要根据实际路径调整）
-	certPath := "/root/go/src/certificate-traceability/network/crypto-config/peerOrganizations/cert.example.com/users/User1@cert.example.com/msp/signcerts/User1@cert.example.com-cert.pem"
-	keyPath := "/root/go/src/certificate-traceability/network/crypto-config/peerOrganizations/cert.example.com/users/User1@cert.example.com/msp/keystore/"
+	// TLS证书和钱包路径（需要根据实际路径调整，钱包由start-network.sh生成）
 	tlsCertPath := "/root/go/src/certificate-traceability/network/crypto-config/peerOrganizations/cert.example.com/peers/peer0.cert.example.com/tls/ca.crt"
+	walletPath := os.Getenv("FABRIC_WALLET")
+	if walletPath == "" {
+		walletPath = "/root/go/src/certificate-traceability/network/wallet/wallet.json"
+	}
+
+	users, err := loadWallet(walletPath)
+	if err != nil {
+		return nil, err
+	}
 
-	// 连接到peer
+	// 连接到peer，各用户的网关共用此连接
 	conn, err := newGrpcConnection(tlsCertPath, "peer0.cert.example.com:7051")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create gRPC connection: %v", err)
 	}
 
+	return &FabricClient{
+		connection: conn,
+		users:      users,
+		contracts:  map[string]*Contract{},
+	}, nil
+}
+
+func (fc *FabricClient) Close() {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	for _, contract := range fc.contracts {
+		contract.gateway.Close()
+	}
+	if fc.connection != nil {
+		fc.connection.Close()
+	}
+}
+
+// Authenticate 根据访问令牌查找钱包用户，返回用户名
+func (fc *FabricClient) Authenticate(token string) (string, error) {
+	if token == "" {
+		return "", ErrUnauthenticated
+	}
+
+	digest := sha256.Sum256([]byte(token))
+	tokenHash := hex.EncodeToString(digest[:])
+	for _, user := range fc.users {
+		if subtle.ConstantTimeCompare([]byte(tokenHash), []byte(strings.ToLower(user.TokenSHA256))) == 1 {
+			return user.Name, nil
+		}
+	}
+
+	return "", ErrUnauthenticated
+}
+
+// Contract 返回以指定用户身份调用的合约，首次调用时连接该用户的网关
+func (fc *FabricClient) Contract(userName string) (*Contract, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if contract, ok := fc.contracts[userName]; ok {
+		return contract, nil
+	}
+
+	var user *WalletUser
+	for i := range fc.users {
+		if fc.users[i].Name == userName {
+			user = &fc.users[i]
+			break
+		}
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user %s is not in the wallet", userName)
+	}
+
 	// 创建身份
-	id, err := newIdentity(certPath, "CertOrgMSP")
+	id, err := newIdentity(user.CertPath, user.MSPID)
 	if err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to create identity: %v", err)
+		return nil, fmt.Errorf("failed to create identity for %s: %v", userName, err)
 	}
 
 	// 创建签名
-	sign, err := newSign(keyPath)
+	sign, err := newSign(user.KeyPath)
 	if err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to create sign: %v", err)
+		return nil, fmt.Errorf("failed to create sign for %s: %v", userName, err)
 	}
 
 	// 创建网关
-	gateway, err := client.Connect(id, client.WithSign(sign), client.WithClientConnection(conn))
+	gateway, err := client.Connect(id, client.WithSign(sign), client.WithClientConnection(fc.connection))
 	if err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to connect to gateway: %v", err)
+		return nil, fmt.Errorf("failed to connect to gateway as %s: %v", userName, err)
 	}
 
-	// 获取网络
-	network := gateway.GetNetwork("mychannel")
-
-	// 获取合约
-	contract := network.GetContract("certificate")
+	contract := &Contract{
+		gateway:  gateway,
+		contract: gateway.GetNetwork("mychannel").GetContract("certificate"),
+	}
+	fc.contracts[userName] = contract
 
-	return &FabricClient{
-		gateway:    gateway,
-		network:    network,
-		contract:   contract,
-		connection: conn,
-	}, nil
+	return contract, nil
 }
 
-func (fc *FabricClient) Close() {
-	if fc.gateway != nil {
-		fc.gateway.Close()
-	}
-	if fc.connection != nil {
-		fc.connection.Close()
+func (c *Contract) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	result, err := c.contract.SubmitTransaction(name, args...)
+	if err != nil {
+		return nil, newTransactionError(fmt.Sprintf("failed to submit transaction %s", name), err)
 	}
+	return result, nil
 }
 
-func (fc *FabricClient) SubmitTransaction(name string, args ...string) ([]byte, error) {
-	result, err := fc.contract.SubmitTransaction(name, args...)
+// SubmitTransactionWithTransient 提交交易并通过transient map传递私有数据，私有数据不会写入交易提案
+func (c *Contract) SubmitTransactionWithTransient(name string, transient map[string][]byte, args ...string) ([]byte, error) {
+	result, err := c.contract.Submit(name, client.WithArguments(args...), client.WithTransient(transient))
 	if err != nil {
-		return nil, fmt.Errorf("failed to submit transaction %s: %v", name, err)
+		return nil, newTransactionError(fmt.Sprintf("failed to submit transaction %s", name), err)
 	}
 	return result, nil
 }
 
-func (fc *FabricClient) EvaluateTransaction(name string, args ...string) ([]byte, error) {
-	result, err := fc.contract.EvaluateTransaction(name, args...)
+func (c *Contract) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	result, err := c.contract.EvaluateTransaction(name, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to evaluate transaction %s: %v", name, err)
+		return nil, newTransactionError(fmt.Sprintf("failed to evaluate transaction %s", name), err)
 	}
 	return result, nil
 }
 
+// newTransactionError 提取网关错误中的链码错误详情并识别错误类别
+func newTransactionError(prefix string, err error) error {
+	messages := []string{err.Error()}
+	for _, detail := range status.Convert(err).Details() {
+		if errDetail, ok := detail.(*gateway.ErrorDetail); ok {
+			messages = append(messages, fmt.Sprintf("%s (%s): %s", errDetail.GetAddress(), errDetail.GetMspId(), errDetail.GetMessage()))
+		}
+	}
+	message := strings.Join(messages, "; ")
+
+	var kind error
+	switch {
+	case strings.Contains(message, "permission denied"):
+		kind = ErrPermissionDenied
+	case strings.Contains(message, "already exists"):
+		kind = ErrAlreadyExists
+	}
+
+	return &TransactionError{
+		message: fmt.Sprintf("%s: %s", prefix, message),
+		kind:    kind,
+	}
+}
+
 func newGrpcConnection(tlsCertPath, peerEndpoint string) (*grpc.ClientConn, error) {
 	certificate, err := loadCertificate(tlsCertPath)
 	if err != nil {
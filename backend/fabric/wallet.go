@@ -0,0 +1,56 @@
+package fabric
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// WalletUser 后端可代为签名的用户。调用者以访问令牌认证后，交易使用该用户的证书和私钥提交，
+// 链码据此识别真实的调用者（MSP、证书主题和cert.role属性）
+type WalletUser struct {
+	Name        string `json:"name"`
+	MSPID       string `json:"mspId"`
+	CertPath    string `json:"certPath"`    // 签名证书（PEM）
+	KeyPath     string `json:"keyPath"`     // 私钥所在目录
+	TokenSHA256 string `json:"tokenSha256"` // 访问令牌的SHA-256哈希（十六进制），钱包中不保存令牌原文
+}
+
+// wallet 钱包文件格式
+type wallet struct {
+	Users []WalletUser `json:"users"`
+}
+
+// loadWallet 读取钱包文件，用户名和令牌哈希不得为空或重复
+func loadWallet(path string) ([]WalletUser, error) {
+	walletJSON, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wallet file: %v", err)
+	}
+
+	var w wallet
+	if err := json.Unmarshal(walletJSON, &w); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal wallet file %s: %v", path, err)
+	}
+	if len(w.Users) == 0 {
+		return nil, fmt.Errorf("wallet file %s has no users", path)
+	}
+
+	names := map[string]bool{}
+	tokens := map[string]bool{}
+	for _, user := range w.Users {
+		if user.Name == "" || user.MSPID == "" || user.CertPath == "" || user.KeyPath == "" || user.TokenSHA256 == "" {
+			return nil, fmt.Errorf("wallet user %q is missing name, mspId, certPath, keyPath or tokenSha256", user.Name)
+		}
+		if names[user.Name] {
+			return nil, fmt.Errorf("wallet user %s is listed more than once", user.Name)
+		}
+		if tokens[user.TokenSHA256] {
+			return nil, fmt.Errorf("wallet user %s shares its token with another user", user.Name)
+		}
+		names[user.Name] = true
+		tokens[user.TokenSHA256] = true
+	}
+
+	return w.Users, nil
+}
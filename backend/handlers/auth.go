@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"certificate-backend/fabric"
+)
+
+// contractKey 认证后调用者的合约在gin上下文中的键
+const contractKey = "fabricContract"
+
+// Authenticate 以Authorization: Bearer <令牌>认证调用者，之后的交易均以调用者本人的Fabric身份签名，
+// 链码的组织、角色和职责分离检查因此作用于真实的操作人
+func Authenticate(fabricClient *fabric.FabricClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+
+		user, err := fabricClient.Authenticate(token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing or invalid access token"})
+			return
+		}
+
+		contract, err := fabricClient.Contract(user)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(contractKey, contract)
+		c.Next()
+	}
+}
+
+// transactor 以调用者身份提交和查询交易，由*fabric.Contract实现
+type transactor interface {
+	SubmitTransaction(name string, args ...string) ([]byte, error)
+	SubmitTransactionWithTransient(name string, transient map[string][]byte, args ...string) ([]byte, error)
+	EvaluateTransaction(name string, args ...string) ([]byte, error)
+}
+
+// userContract 返回以当前调用者身份调用的合约
+func userContract(c *gin.Context) transactor {
+	return c.MustGet(contractKey).(transactor)
+}
@@ -1,26 +1,26 @@
 package handlers
 
 import (
-	"crypto/sha256"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"time"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"certificate-backend/crypto"
 	"certificate-backend/fabric"
 	"certificate-backend/models"
 )
 
-type CertificateHandler struct {
-	fabricClient *fabric.FabricClient
-}
+type CertificateHandler struct{}
 
-func NewCertificateHandler(fabricClient *fabric.FabricClient) *CertificateHandler {
-	return &CertificateHandler{
-		fabricClient: fabricClient,
-	}
+func NewCertificateHandler() *CertificateHandler {
+	return &CertificateHandler{}
 }
 
 // CreateCertificate 创建证书
@@ -31,6 +31,13 @@ func (h *CertificateHandler) CreateCertificate(c *gin.Context) {
 		return
 	}
 
+	// 送检单位和接收证书的组织取自登记的客户
+	customer, err := readCustomer(userContract(c), req.CustomerID)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
 	// 生成证书ID
 	certificateID := uuid.New().String()
 
@@ -38,14 +45,16 @@ func (h *CertificateHandler) CreateCertificate(c *gin.Context) {
 	cert := models.Certificate{
 		ID:            certificateID,
 		CertificateNo: req.CertificateNo,
-		TestUnit:      req.TestUnit,
+		CustomerID:    customer.ID,
+		TestUnit:      customer.Name,
+		OwnerMSP:      customer.ContactMSP,
+		Item:          req.Item,
 		TestDate:      req.TestDate,
-		TestData:      req.TestData,
+		Environment:   req.Environment,
 		InspectionOrg: req.InspectionOrg,
 		Inspector:     req.Inspector,
 		ValidUntil:    req.ValidUntil,
-		CreatedBy:     req.CreatedBy,
-		Hash:          h.generateCertificateHash(req),
+		HashAlgorithm: req.HashAlgorithm,
 	}
 
 	// 序列化证书数据
@@ -55,10 +64,17 @@ func (h *CertificateHandler) CreateCertificate(c *gin.Context) {
 		return
 	}
 
+	// 测试数据通过transient map传递，写入私有数据集合
+	transient, err := testDataTransient(req.TestData)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal test data"})
+		return
+	}
+
 	// 调用智能合约创建证书
-	_, err = h.fabricClient.SubmitTransaction("CreateCertificate", certificateID, string(certData))
+	_, err = userContract(c).SubmitTransactionWithTransient("CreateCertificate", transient, certificateID, string(certData))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to create certificate: %v", err)})
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to create certificate: %v", err)})
 		return
 	}
 
@@ -68,13 +84,43 @@ func (h *CertificateHandler) CreateCertificate(c *gin.Context) {
 	})
 }
 
-// GetCertificate 获取证书详情
+// GetCertificate 获取证书详情，状态为链码按有效期计算后的有效状态
 func (h *CertificateHandler) GetCertificate(c *gin.Context) {
 	id := c.Param("id")
 
 	// 调用智能合约读取证书
-	result, err := h.fabricClient.EvaluateTransaction("ReadCertificate", id)
+	result, err := userContract(c).EvaluateTransaction("ReadCertificate", id)
+	if err != nil {
+		if errors.Is(err, fabric.ErrPermissionDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "Certificate not found"})
+		return
+	}
+
+	var cert models.Certificate
+	if err := json.Unmarshal(result, &cert); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal certificate data"})
+		return
+	}
+
+	attachTestData(userContract(c), &cert)
+
+	c.JSON(http.StatusOK, cert)
+}
+
+// GetCertificateByNumber 按证书编号获取证书详情
+func (h *CertificateHandler) GetCertificateByNumber(c *gin.Context) {
+	certificateNo := c.Param("no")
+
+	// 调用智能合约按编号读取证书
+	result, err := userContract(c).EvaluateTransaction("ReadCertificateByNumber", certificateNo)
 	if err != nil {
+		if errors.Is(err, fabric.ErrPermissionDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{"error": "Certificate not found"})
 		return
 	}
@@ -85,9 +131,94 @@ func (h *CertificateHandler) GetCertificate(c *gin.Context) {
 		return
 	}
 
+	attachTestData(userContract(c), &cert)
+
 	c.JSON(http.StatusOK, cert)
 }
 
+// GetCertificateByHash 按证书内容哈希获取证书，哈希可以来自证书的历史版本
+func (h *CertificateHandler) GetCertificateByHash(c *gin.Context) {
+	hash := strings.ToLower(c.Param("hash"))
+
+	// 调用智能合约按哈希读取证书
+	result, err := userContract(c).EvaluateTransaction("ReadCertificateByHash", hash)
+	if err != nil {
+		if errors.Is(err, fabric.ErrPermissionDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "Certificate not found"})
+		return
+	}
+
+	var cert models.Certificate
+	if err := json.Unmarshal(result, &cert); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal certificate data"})
+		return
+	}
+
+	attachTestData(userContract(c), &cert)
+
+	c.JSON(http.StatusOK, models.CertificateHashLookup{
+		Certificate: cert,
+		Current:     cert.Hash == hash,
+	})
+}
+
+// VerifyCertificate 校验证书真伪，可提交证书ID和哈希，或提交完整证书JSON由链码复算哈希
+func (h *CertificateHandler) VerifyCertificate(c *gin.Context) {
+	var req models.VerifyCertificateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, hash := req.ID, req.Hash
+	if req.Certificate != nil {
+		if id == "" {
+			id = req.Certificate.ID
+		}
+
+		certData, err := json.Marshal(req.Certificate)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal certificate data"})
+			return
+		}
+
+		// 按链码的规范化规则序列化证书内容，再按证书记录的哈希算法计算摘要
+		content, err := userContract(c).EvaluateTransaction("GetCanonicalCertificateContent", string(certData))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Failed to compute certificate hash: %v", err)})
+			return
+		}
+		digest, err := crypto.Digest(req.Certificate.HashAlgorithm, content)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		hash = hex.EncodeToString(digest)
+	}
+
+	if id == "" || hash == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "either id and hash or a full certificate is required"})
+		return
+	}
+
+	result, err := userContract(c).EvaluateTransaction("VerifyCertificate", id, hash)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusNotFound), gin.H{"error": fmt.Sprintf("Failed to verify certificate: %v", err)})
+		return
+	}
+
+	var verification models.VerificationResult
+	if err := json.Unmarshal(result, &verification); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal verification result"})
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
 // UpdateCertificate 更新证书
 func (h *CertificateHandler) UpdateCertificate(c *gin.Context) {
 	id := c.Param("id")
@@ -98,8 +229,12 @@ func (h *CertificateHandler) UpdateCertificate(c *gin.Context) {
 	}
 
 	// 获取现有证书
-	existingResult, err := h.fabricClient.EvaluateTransaction("ReadCertificate", id)
+	existingResult, err := userContract(c).EvaluateTransaction("ReadCertificate", id)
 	if err != nil {
+		if errors.Is(err, fabric.ErrPermissionDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{"error": "Certificate not found"})
 		return
 	}
@@ -114,14 +249,24 @@ func (h *CertificateHandler) UpdateCertificate(c *gin.Context) {
 	if req.CertificateNo != "" {
 		existingCert.CertificateNo = req.CertificateNo
 	}
-	if req.TestUnit != "" {
-		existingCert.TestUnit = req.TestUnit
+	if req.CustomerID != "" {
+		customer, err := readCustomer(userContract(c), req.CustomerID)
+		if err != nil {
+			c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+			return
+		}
+		existingCert.CustomerID = customer.ID
+		existingCert.TestUnit = customer.Name
+		existingCert.OwnerMSP = customer.ContactMSP
+	}
+	if req.Item != nil {
+		existingCert.Item = req.Item
 	}
 	if req.TestDate != "" {
 		existingCert.TestDate = req.TestDate
 	}
-	if len(req.TestData) > 0 {
-		existingCert.TestData = req.TestData
+	if req.Environment != nil {
+		existingCert.Environment = req.Environment
 	}
 	if req.InspectionOrg != "" {
 		existingCert.InspectionOrg = req.InspectionOrg
@@ -132,9 +277,9 @@ func (h *CertificateHandler) UpdateCertificate(c *gin.Context) {
 	if req.ValidUntil != "" {
 		existingCert.ValidUntil = req.ValidUntil
 	}
-
-	// 重新生成哈希
-	existingCert.Hash = h.generateCertificateHashFromCert(existingCert)
+	if req.HashAlgorithm != "" {
+		existingCert.HashAlgorithm = req.HashAlgorithm
+	}
 
 	// 序列化更新后的证书
 	updatedCertData, err := json.Marshal(existingCert)
@@ -143,17 +288,71 @@ func (h *CertificateHandler) UpdateCertificate(c *gin.Context) {
 		return
 	}
 
+	// 仅在提供新测试数据时通过transient map传递，否则链码沿用原私有数据
+	transient, err := testDataTransient(req.TestData)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal test data"})
+		return
+	}
+
 	// 调用智能合约更新证书
-	_, err = h.fabricClient.SubmitTransaction("UpdateCertificate", id, string(updatedCertData), req.Operator)
+	_, err = userContract(c).SubmitTransactionWithTransient("UpdateCertificate", transient, id, string(updatedCertData))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to update certificate: %v", err)})
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to update certificate: %v", err)})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Certificate updated successfully"})
 }
 
-// IssueCertificate 签发证书
+// SubmitForReview 提交证书核验
+func (h *CertificateHandler) SubmitForReview(c *gin.Context) {
+	id := c.Param("id")
+
+	// 调用智能合约提交核验
+	_, err := userContract(c).SubmitTransaction("SubmitForReview", id)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to submit certificate for review: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Certificate submitted for review successfully"})
+}
+
+// ReviewCertificate 核验证书（通过或驳回）
+func (h *CertificateHandler) ReviewCertificate(c *gin.Context) {
+	id := c.Param("id")
+	var req models.ReviewCertificateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 调用智能合约核验证书
+	_, err := userContract(c).SubmitTransaction("ReviewCertificate", id, strconv.FormatBool(*req.Approved), req.Comments)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to review certificate: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Certificate reviewed successfully"})
+}
+
+// ApproveCertificate 批准证书
+func (h *CertificateHandler) ApproveCertificate(c *gin.Context) {
+	id := c.Param("id")
+
+	// 调用智能合约批准证书
+	_, err := userContract(c).SubmitTransaction("ApproveCertificate", id)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to approve certificate: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Certificate approved successfully"})
+}
+
+// IssueCertificate 签发证书，请求需附检验员对证书内容哈希的签名
 func (h *CertificateHandler) IssueCertificate(c *gin.Context) {
 	id := c.Param("id")
 	var req models.IssueCertificateRequest
@@ -162,16 +361,148 @@ func (h *CertificateHandler) IssueCertificate(c *gin.Context) {
 		return
 	}
 
-	// 调用智能合约签发证书
-	_, err := h.fabricClient.SubmitTransaction("IssueCertificate", id, req.Operator)
+	// 调用智能合约签发证书，签发人由链码从调用者身份中获取，签名及签名者身份由链码校验
+	_, err := userContract(c).SubmitTransaction("IssueCertificate", id, req.Signature, req.SignerCertificate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to issue certificate: %v", err)})
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to issue certificate: %v", err)})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Certificate issued successfully"})
 }
 
+// SuspendCertificate 暂停证书
+func (h *CertificateHandler) SuspendCertificate(c *gin.Context) {
+	id := c.Param("id")
+	var req models.SuspendCertificateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 调用智能合约暂停证书
+	_, err := userContract(c).SubmitTransaction("SuspendCertificate", id, req.ReasonCode, req.Details)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to suspend certificate: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Certificate suspended successfully"})
+}
+
+// ReinstateCertificate 恢复被暂停的证书
+func (h *CertificateHandler) ReinstateCertificate(c *gin.Context) {
+	id := c.Param("id")
+	var req models.ReinstateCertificateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 调用智能合约恢复证书
+	_, err := userContract(c).SubmitTransaction("ReinstateCertificate", id, req.Details)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to reinstate certificate: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Certificate reinstated successfully"})
+}
+
+// ReissueCertificate 换发证书，以原证书内容创建新的草稿证书
+func (h *CertificateHandler) ReissueCertificate(c *gin.Context) {
+	id := c.Param("id")
+	var req models.ReissueCertificateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 生成新证书ID
+	newCertificateID := uuid.New().String()
+
+	// 新证书复制原测试数据，使用新的盐值
+	salt, err := newSalt()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate test data salt"})
+		return
+	}
+
+	// 调用智能合约换发证书
+	_, err = userContract(c).SubmitTransactionWithTransient("ReissueCertificate", map[string][]byte{"salt": salt}, id, newCertificateID, req.CertificateNo)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to reissue certificate: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":       "Certificate reissued successfully",
+		"certificateId": newCertificateID,
+	})
+}
+
+// GetCertificateVersions 获取证书的完整版本链
+func (h *CertificateHandler) GetCertificateVersions(c *gin.Context) {
+	id := c.Param("id")
+
+	// 调用智能合约获取版本链
+	result, err := userContract(c).EvaluateTransaction("GetCertificateVersionChain", id)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to get certificate versions: %v", err)})
+		return
+	}
+
+	var versions []models.Certificate
+	if err := json.Unmarshal(result, &versions); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal certificate versions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// GetTraceabilityChain 获取证书经测量设备到计量基准的溯源链
+func (h *CertificateHandler) GetTraceabilityChain(c *gin.Context) {
+	id := c.Param("id")
+
+	// 调用智能合约获取溯源链
+	result, err := userContract(c).EvaluateTransaction("GetTraceabilityChain", id)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to get traceability chain: %v", err)})
+		return
+	}
+
+	var chain models.TraceabilityNode
+	if err := json.Unmarshal(result, &chain); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal traceability chain"})
+		return
+	}
+
+	c.JSON(http.StatusOK, chain)
+}
+
+// GetInstrumentCertificates 按被校设备出厂编号获取该设备的历次证书
+func (h *CertificateHandler) GetInstrumentCertificates(c *gin.Context) {
+	serial := c.Param("serial")
+
+	// 调用智能合约查询设备的校准历史
+	result, err := userContract(c).EvaluateTransaction("QueryCertificatesBySerialNumber", serial)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to query instrument certificates: %v", err)})
+		return
+	}
+
+	certificates := []models.Certificate{}
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &certificates); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal certificates data"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, certificates)
+}
+
 // RevokeCertificate 撤销证书
 func (h *CertificateHandler) RevokeCertificate(c *gin.Context) {
 	id := c.Param("id")
@@ -182,23 +513,46 @@ func (h *CertificateHandler) RevokeCertificate(c *gin.Context) {
 	}
 
 	// 调用智能合约撤销证书
-	_, err := h.fabricClient.SubmitTransaction("RevokeCertificate", id, req.Operator, req.Reason)
+	_, err := userContract(c).SubmitTransaction("RevokeCertificate", id, req.Reason)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to revoke certificate: %v", err)})
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to revoke certificate: %v", err)})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Certificate revoked successfully"})
 }
 
+// ExpireCertificates 将超过有效期的已签发证书批量置为过期
+func (h *CertificateHandler) ExpireCertificates(c *gin.Context) {
+	// 调用智能合约执行过期处理
+	result, err := userContract(c).SubmitTransaction("ExpireCertificates")
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to expire certificates: %v", err)})
+		return
+	}
+
+	var expired []string
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &expired); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal expired certificates"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Expired certificates processed successfully",
+		"expired": expired,
+	})
+}
+
 // GetCertificateHistory 获取证书历史记录
 func (h *CertificateHandler) GetCertificateHistory(c *gin.Context) {
 	id := c.Param("id")
 
 	// 调用智能合约获取证书历史
-	result, err := h.fabricClient.EvaluateTransaction("GetCertificateHistory", id)
+	result, err := userContract(c).EvaluateTransaction("GetCertificateHistory", id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to get certificate history: %v", err)})
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to get certificate history: %v", err)})
 		return
 	}
 
@@ -211,7 +565,7 @@ func (h *CertificateHandler) GetCertificateHistory(c *gin.Context) {
 	c.JSON(http.StatusOK, history)
 }
 
-// QueryCertificates 查询证书
+// QueryCertificates 查询证书，所有提供的过滤条件同时生效
 func (h *CertificateHandler) QueryCertificates(c *gin.Context) {
 	var req models.QueryCertificatesRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -219,82 +573,135 @@ func (h *CertificateHandler) QueryCertificates(c *gin.Context) {
 		return
 	}
 
-	var result []byte
-	var err error
+	filter := models.CertificateFilter{
+		CustomerID:     req.CustomerID,
+		TestUnit:       req.TestUnit,
+		Status:         req.Status,
+		InspectionOrg:  req.InspectionOrg,
+		Inspector:      req.Inspector,
+		TestDateFrom:   req.TestDateFrom,
+		TestDateTo:     req.TestDateTo,
+		IssuedDateFrom: req.IssuedDateFrom,
+		IssuedDateTo:   req.IssuedDateTo,
+		ValidUntilFrom: req.ValidUntilFrom,
+		ValidUntilTo:   req.ValidUntilTo,
+		SortBy:         req.SortBy,
+		SortOrder:      req.SortOrder,
+	}
 
-	// 根据查询参数调用不同的智能合约函数
-	if req.TestUnit != "" {
-		result, err = h.fabricClient.EvaluateTransaction("QueryCertificatesByTestUnit", req.TestUnit)
-	} else if req.Status != "" {
-		result, err = h.fabricClient.EvaluateTransaction("QueryCertificatesByStatus", req.Status)
-	} else if req.InspectionOrg != "" {
-		result, err = h.fabricClient.EvaluateTransaction("QueryCertificatesByInspectionOrg", req.InspectionOrg)
-	} else {
-		result, err = h.fabricClient.EvaluateTransaction("GetAllCertificates")
+	filterData, err := json.Marshal(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal query filter"})
+		return
 	}
 
+	// 指定pageSize时使用分页查询，响应中返回下一页的bookmark
+	if req.PageSize > 0 {
+		result, err := userContract(c).EvaluateTransaction("QueryCertificatesWithPagination", string(filterData), strconv.Itoa(int(req.PageSize)), req.Bookmark)
+		if err != nil {
+			c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to query certificates: %v", err)})
+			return
+		}
+
+		var page models.PaginatedCertificates
+		if err := json.Unmarshal(result, &page); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal certificates data"})
+			return
+		}
+
+		c.JSON(http.StatusOK, page)
+		return
+	}
+
+	result, err := userContract(c).EvaluateTransaction("QueryCertificates", string(filterData))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to query certificates: %v", err)})
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to query certificates: %v", err)})
 		return
 	}
 
 	var certificates []models.Certificate
-	if err := json.Unmarshal(result, &certificates); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal certificates data"})
-		return
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &certificates); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal certificates data"})
+			return
+		}
 	}
 
 	c.JSON(http.StatusOK, certificates)
 }
 
-// generateCertificateHash 生成证书哈希
-func (h *CertificateHandler) generateCertificateHash(req models.CreateCertificateRequest) string {
-	data := fmt.Sprintf("%s%s%s%s%s%s%s",
-		req.CertificateNo,
-		req.TestUnit,
-		req.TestDate,
-		req.InspectionOrg,
-		req.Inspector,
-		req.ValidUntil,
-		time.Now().Format(time.RFC3339))
-	
-	// 添加测试数据到哈希计算
-	for _, testData := range req.TestData {
-		data += fmt.Sprintf("%s%f%s%f%s%s",
-			testData.Parameter,
-			testData.MeasuredValue,
-			testData.Unit,
-			testData.Uncertainty,
-			testData.Method,
-			testData.Equipment)
-	}
-
-	hash := sha256.Sum256([]byte(data))
-	return fmt.Sprintf("%x", hash)
+// readTestData 读取证书的私有测试数据
+func readTestData(contract transactor, id string) ([]models.TestDataItem, error) {
+	result, err := contract.EvaluateTransaction("ReadCertificateTestData", id)
+	if err != nil {
+		return nil, err
+	}
+
+	var testData []models.TestDataItem
+	if err := json.Unmarshal(result, &testData); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal test data: %v", err)
+	}
+
+	return testData, nil
+}
+
+// attachTestData 在调用者有权读取时附加证书的私有测试数据及其盐值，
+// 盐值计入证书内容哈希，须与测试数据一同打印在证书上
+func attachTestData(contract transactor, cert *models.Certificate) {
+	if cert.TestDataHash == "" {
+		return
+	}
+
+	testData, err := readTestData(contract, cert.ID)
+	if err != nil {
+		return
+	}
+	salt, err := contract.EvaluateTransaction("ReadCertificateSalt", cert.ID)
+	if err != nil {
+		return
+	}
+	cert.TestData = testData
+	cert.Salt = string(salt)
 }
 
-// generateCertificateHashFromCert 从证书对象生成哈希
-func (h *CertificateHandler) generateCertificateHashFromCert(cert models.Certificate) string {
-	data := fmt.Sprintf("%s%s%s%s%s%s%s",
-		cert.CertificateNo,
-		cert.TestUnit,
-		cert.TestDate,
-		cert.InspectionOrg,
-		cert.Inspector,
-		cert.ValidUntil,
-		cert.CreatedAt)
-	
-	// 添加测试数据到哈希计算
-	for _, testData := range cert.TestData {
-		data += fmt.Sprintf("%s%f%s%f%s%s",
-			testData.Parameter,
-			testData.MeasuredValue,
-			testData.Unit,
-			testData.Uncertainty,
-			testData.Method,
-			testData.Equipment)
-	}
-
-	hash := sha256.Sum256([]byte(data))
-	return fmt.Sprintf("%x", hash)
-}
\ No newline at end of file
+// testDataTransient 构造传递测试数据及其随机盐值的transient map，未提供测试数据时为空
+func testDataTransient(testData []models.TestDataItem) (map[string][]byte, error) {
+	transient := map[string][]byte{}
+	if len(testData) == 0 {
+		return transient, nil
+	}
+
+	testDataJSON, err := json.Marshal(testData)
+	if err != nil {
+		return nil, err
+	}
+	salt, err := newSalt()
+	if err != nil {
+		return nil, err
+	}
+	transient["testData"] = testDataJSON
+	transient["salt"] = salt
+
+	return transient, nil
+}
+
+// newSalt 生成私有测试数据的随机盐值，防止通过公开哈希穷举测试数据
+func newSalt() ([]byte, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %v", err)
+	}
+
+	return salt, nil
+}
+
+// errorStatus 根据链码错误类别确定HTTP状态码，权限不足时返回403，重复时返回409
+func errorStatus(err error, defaultStatus int) int {
+	switch {
+	case errors.Is(err, fabric.ErrPermissionDenied):
+		return http.StatusForbidden
+	case errors.Is(err, fabric.ErrAlreadyExists):
+		return http.StatusConflict
+	}
+	return defaultStatus
+}
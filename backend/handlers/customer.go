@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"certificate-backend/models"
+)
+
+type CustomerHandler struct{}
+
+func NewCustomerHandler() *CustomerHandler {
+	return &CustomerHandler{}
+}
+
+// readCustomer 读取登记的客户，证书的送检单位和接收组织以此为准
+func readCustomer(contract transactor, id string) (*models.Customer, error) {
+	result, err := contract.EvaluateTransaction("ReadCustomer", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read customer %s: %w", id, err)
+	}
+
+	var customer models.Customer
+	if err := json.Unmarshal(result, &customer); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal customer data")
+	}
+
+	return &customer, nil
+}
+
+// CreateCustomer 登记客户
+func (h *CustomerHandler) CreateCustomer(c *gin.Context) {
+	var req models.CreateCustomerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 生成客户ID
+	customerID := uuid.New().String()
+
+	customer := models.Customer{
+		ID:         customerID,
+		CreditCode: req.CreditCode,
+		Name:       req.Name,
+		Aliases:    req.Aliases,
+		ContactMSP: req.ContactMSP,
+	}
+
+	customerData, err := json.Marshal(customer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal customer data"})
+		return
+	}
+
+	_, err = userContract(c).SubmitTransaction("CreateCustomer", customerID, string(customerData))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to create customer: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Customer created successfully",
+		"customerId": customerID,
+	})
+}
+
+// GetCustomer 获取客户详情
+func (h *CustomerHandler) GetCustomer(c *gin.Context) {
+	customer, err := readCustomer(userContract(c), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, customer)
+}
+
+// GetCustomerByCreditCode 按统一社会信用代码获取客户
+func (h *CustomerHandler) GetCustomerByCreditCode(c *gin.Context) {
+	creditCode := c.Param("code")
+
+	result, err := userContract(c).EvaluateTransaction("ReadCustomerByCreditCode", creditCode)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		return
+	}
+
+	var customer models.Customer
+	if err := json.Unmarshal(result, &customer); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal customer data"})
+		return
+	}
+
+	c.JSON(http.StatusOK, customer)
+}
+
+// UpdateCustomer 更新客户名称、别名和信用代码，接收证书的组织不可变更
+func (h *CustomerHandler) UpdateCustomer(c *gin.Context) {
+	id := c.Param("id")
+	var req models.UpdateCustomerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 获取现有客户
+	customer, err := readCustomer(userContract(c), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		return
+	}
+
+	// 只更新提供的字段
+	if req.CreditCode != "" {
+		customer.CreditCode = req.CreditCode
+	}
+	if req.Name != "" {
+		customer.Name = req.Name
+	}
+	if req.Aliases != nil {
+		customer.Aliases = req.Aliases
+	}
+
+	customerData, err := json.Marshal(customer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal customer data"})
+		return
+	}
+
+	_, err = userContract(c).SubmitTransaction("UpdateCustomer", id, string(customerData))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to update customer: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Customer updated successfully"})
+}
+
+// GetAllCustomers 获取全部客户
+func (h *CustomerHandler) GetAllCustomers(c *gin.Context) {
+	result, err := userContract(c).EvaluateTransaction("GetAllCustomers")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to get customers: %v", err)})
+		return
+	}
+
+	customers := []models.Customer{}
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &customers); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal customer data"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, customers)
+}
+
+// GetCustomerCertificates 获取客户的全部证书，非检验机构只能查询发给自己的证书
+func (h *CustomerHandler) GetCustomerCertificates(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := userContract(c).EvaluateTransaction("QueryCertificatesByCustomer", id)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to query certificates: %v", err)})
+		return
+	}
+
+	certificates := []models.Certificate{}
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &certificates); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal certificates data"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, certificates)
+}
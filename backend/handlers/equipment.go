@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"certificate-backend/models"
+)
+
+type EquipmentHandler struct{}
+
+func NewEquipmentHandler() *EquipmentHandler {
+	return &EquipmentHandler{}
+}
+
+// CreateEquipment 登记测量设备
+func (h *EquipmentHandler) CreateEquipment(c *gin.Context) {
+	var req models.CreateEquipmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 生成设备ID
+	equipmentID := uuid.New().String()
+
+	equipment := models.Equipment{
+		ID:                       equipmentID,
+		Model:                    req.Model,
+		SerialNumber:             req.SerialNumber,
+		OwnerOrg:                 req.OwnerOrg,
+		CalibrationCertificateID: req.CalibrationCertificateID,
+		IsReferenceStandard:      req.IsReferenceStandard,
+	}
+
+	equipmentData, err := json.Marshal(equipment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal equipment data"})
+		return
+	}
+
+	_, err = userContract(c).SubmitTransaction("CreateEquipment", equipmentID, string(equipmentData))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to create equipment: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "Equipment created successfully",
+		"equipmentId": equipmentID,
+	})
+}
+
+// GetEquipment 获取测量设备详情
+func (h *EquipmentHandler) GetEquipment(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := userContract(c).EvaluateTransaction("ReadEquipment", id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Equipment not found"})
+		return
+	}
+
+	var equipment models.Equipment
+	if err := json.Unmarshal(result, &equipment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal equipment data"})
+		return
+	}
+
+	c.JSON(http.StatusOK, equipment)
+}
+
+// UpdateEquipment 更新测量设备，如登记重新校准后的校准证书
+func (h *EquipmentHandler) UpdateEquipment(c *gin.Context) {
+	id := c.Param("id")
+	var req models.UpdateEquipmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 获取现有设备
+	existingResult, err := userContract(c).EvaluateTransaction("ReadEquipment", id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Equipment not found"})
+		return
+	}
+
+	var equipment models.Equipment
+	if err := json.Unmarshal(existingResult, &equipment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal existing equipment"})
+		return
+	}
+
+	// 只更新提供的字段
+	if req.Model != "" {
+		equipment.Model = req.Model
+	}
+	if req.SerialNumber != "" {
+		equipment.SerialNumber = req.SerialNumber
+	}
+	if req.OwnerOrg != "" {
+		equipment.OwnerOrg = req.OwnerOrg
+	}
+	if req.CalibrationCertificateID != "" {
+		equipment.CalibrationCertificateID = req.CalibrationCertificateID
+	}
+	if req.IsReferenceStandard != nil {
+		equipment.IsReferenceStandard = *req.IsReferenceStandard
+	}
+
+	equipmentData, err := json.Marshal(equipment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal equipment data"})
+		return
+	}
+
+	_, err = userContract(c).SubmitTransaction("UpdateEquipment", id, string(equipmentData))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to update equipment: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Equipment updated successfully"})
+}
+
+// GetAllEquipment 获取全部测量设备
+func (h *EquipmentHandler) GetAllEquipment(c *gin.Context) {
+	result, err := userContract(c).EvaluateTransaction("GetAllEquipment")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to get equipment: %v", err)})
+		return
+	}
+
+	equipmentList := []models.Equipment{}
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &equipmentList); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal equipment data"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, equipmentList)
+}
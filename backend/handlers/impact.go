@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"certificate-backend/models"
+)
+
+// 影响分析的源头类型，与链码一致
+const (
+	impactSourceCertificate = "certificate"
+	impactSourceEquipment   = "equipment"
+)
+
+// getImpactReport 调用智能合约获取影响树
+func getImpactReport(c *gin.Context, contract transactor, function string, id string) {
+	result, err := contract.EvaluateTransaction(function, id)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to analyze impact: %v", err)})
+		return
+	}
+
+	var report models.ImpactReport
+	if err := json.Unmarshal(result, &report); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal impact report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// recallAffectedCertificates 先以查询执行影响分析，再将受影响的证书ID提交给智能合约批量暂停或标记。
+// 富查询结果在提交时不会重新校验，因此不在提交交易中查找受影响的证书
+func recallAffectedCertificates(c *gin.Context, contract transactor, sourceType string, analyzeFunction string, id string) {
+	var req models.RecallCertificatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := contract.EvaluateTransaction(analyzeFunction, id)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to analyze impact: %v", err)})
+		return
+	}
+
+	var report models.ImpactReport
+	if err := json.Unmarshal(result, &report); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal impact report"})
+		return
+	}
+
+	certificateIDs, err := json.Marshal(report.AffectedCertificateIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal affected certificates"})
+		return
+	}
+
+	result, err = contract.SubmitTransaction("RecallAffectedCertificates", sourceType, id, req.Action, req.Reason, string(certificateIDs))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to recall certificates: %v", err)})
+		return
+	}
+
+	var recalled []string
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &recalled); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal recalled certificates"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Affected certificates recalled successfully",
+		"recalled": recalled,
+	})
+}
+
+// GetCertificateImpact 获取直接或间接依赖该校准证书的全部证书
+func (h *CertificateHandler) GetCertificateImpact(c *gin.Context) {
+	getImpactReport(c, userContract(c), "AnalyzeCertificateImpact", c.Param("id"))
+}
+
+// RecallAffectedCertificates 批量暂停或标记依赖该校准证书的证书
+func (h *CertificateHandler) RecallAffectedCertificates(c *gin.Context) {
+	recallAffectedCertificates(c, userContract(c), impactSourceCertificate, "AnalyzeCertificateImpact", c.Param("id"))
+}
+
+// GetEquipmentImpact 获取直接或间接使用该设备出具的全部证书
+func (h *EquipmentHandler) GetEquipmentImpact(c *gin.Context) {
+	getImpactReport(c, userContract(c), "AnalyzeEquipmentImpact", c.Param("id"))
+}
+
+// RecallAffectedCertificates 批量暂停或标记使用该设备出具的证书
+func (h *EquipmentHandler) RecallAffectedCertificates(c *gin.Context) {
+	recallAffectedCertificates(c, userContract(c), impactSourceEquipment, "AnalyzeEquipmentImpact", c.Param("id"))
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"certificate-backend/models"
+)
+
+type InspectorHandler struct{}
+
+func NewInspectorHandler() *InspectorHandler {
+	return &InspectorHandler{}
+}
+
+// CreateInspector 登记检验员，需组织管理员身份
+func (h *InspectorHandler) CreateInspector(c *gin.Context) {
+	var req models.CreateInspectorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	inspector := models.Inspector{
+		ID:                   req.ID,
+		Name:                 req.Name,
+		Identity:             req.Identity,
+		AuthorizedParameters: req.AuthorizedParameters,
+		AuthorizedMethods:    req.AuthorizedMethods,
+		QualifiedFrom:        req.QualifiedFrom,
+		QualifiedUntil:       req.QualifiedUntil,
+	}
+
+	inspectorData, err := json.Marshal(inspector)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal inspector data"})
+		return
+	}
+
+	_, err = userContract(c).SubmitTransaction("CreateInspector", req.ID, string(inspectorData))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to create inspector: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "Inspector created successfully",
+		"inspectorId": req.ID,
+	})
+}
+
+// GetInspector 获取检验员详情
+func (h *InspectorHandler) GetInspector(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := userContract(c).EvaluateTransaction("ReadInspector", id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Inspector not found"})
+		return
+	}
+
+	var inspector models.Inspector
+	if err := json.Unmarshal(result, &inspector); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal inspector data"})
+		return
+	}
+
+	c.JSON(http.StatusOK, inspector)
+}
+
+// UpdateInspector 更新检验员资质和授权范围，需组织管理员身份
+func (h *InspectorHandler) UpdateInspector(c *gin.Context) {
+	id := c.Param("id")
+	var req models.UpdateInspectorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 获取现有检验员
+	existingResult, err := userContract(c).EvaluateTransaction("ReadInspector", id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Inspector not found"})
+		return
+	}
+
+	var inspector models.Inspector
+	if err := json.Unmarshal(existingResult, &inspector); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal existing inspector"})
+		return
+	}
+
+	// 只更新提供的字段
+	if req.Name != "" {
+		inspector.Name = req.Name
+	}
+	if req.Identity != "" {
+		inspector.Identity = req.Identity
+	}
+	if req.AuthorizedParameters != nil {
+		inspector.AuthorizedParameters = req.AuthorizedParameters
+	}
+	if req.AuthorizedMethods != nil {
+		inspector.AuthorizedMethods = req.AuthorizedMethods
+	}
+	if req.QualifiedFrom != "" {
+		inspector.QualifiedFrom = req.QualifiedFrom
+	}
+	if req.QualifiedUntil != "" {
+		inspector.QualifiedUntil = req.QualifiedUntil
+	}
+
+	inspectorData, err := json.Marshal(inspector)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal inspector data"})
+		return
+	}
+
+	_, err = userContract(c).SubmitTransaction("UpdateInspector", id, string(inspectorData))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to update inspector: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Inspector updated successfully"})
+}
+
+// DeleteInspector 注销检验员，需组织管理员身份
+func (h *InspectorHandler) DeleteInspector(c *gin.Context) {
+	id := c.Param("id")
+
+	_, err := userContract(c).SubmitTransaction("DeleteInspector", id)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to delete inspector: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Inspector deleted successfully"})
+}
+
+// GetAllInspectors 获取全部检验员
+func (h *InspectorHandler) GetAllInspectors(c *gin.Context) {
+	result, err := userContract(c).EvaluateTransaction("GetAllInspectors")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to get inspectors: %v", err)})
+		return
+	}
+
+	inspectors := []models.Inspector{}
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &inspectors); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal inspector data"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, inspectors)
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"certificate-backend/models"
+)
+
+type TestMethodHandler struct{}
+
+func NewTestMethodHandler() *TestMethodHandler {
+	return &TestMethodHandler{}
+}
+
+// testMethodName 读取路由中的测试方法名称。方法名称为标准编号，可能含有"/"（如GB/T 13992），
+// 因此路由使用通配参数*name，其值以"/"开头
+func testMethodName(c *gin.Context) (string, bool) {
+	name := strings.TrimPrefix(c.Param("name"), "/")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Test method name is required"})
+		return "", false
+	}
+
+	return name, true
+}
+
+// SetTestMethod 登记或更新测试方法的环境条件要求，需组织管理员身份
+func (h *TestMethodHandler) SetTestMethod(c *gin.Context) {
+	name, ok := testMethodName(c)
+	if !ok {
+		return
+	}
+	var req models.SetTestMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	method := models.TestMethod{
+		Name:        name,
+		Temperature: req.Temperature,
+		Humidity:    req.Humidity,
+		Pressure:    req.Pressure,
+	}
+
+	methodData, err := json.Marshal(method)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal test method data"})
+		return
+	}
+
+	_, err = userContract(c).SubmitTransaction("SetTestMethod", name, string(methodData))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to set test method: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Test method saved successfully"})
+}
+
+// GetTestMethod 获取测试方法及其环境条件要求
+func (h *TestMethodHandler) GetTestMethod(c *gin.Context) {
+	name, ok := testMethodName(c)
+	if !ok {
+		return
+	}
+
+	result, err := userContract(c).EvaluateTransaction("ReadTestMethod", name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Test method not found"})
+		return
+	}
+
+	var method models.TestMethod
+	if err := json.Unmarshal(result, &method); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal test method data"})
+		return
+	}
+
+	c.JSON(http.StatusOK, method)
+}
+
+// GetAllTestMethods 获取全部测试方法
+func (h *TestMethodHandler) GetAllTestMethods(c *gin.Context) {
+	result, err := userContract(c).EvaluateTransaction("GetAllTestMethods")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to get test methods: %v", err)})
+		return
+	}
+
+	methods := []models.TestMethod{}
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &methods); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal test method data"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, methods)
+}
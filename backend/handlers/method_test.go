@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeContract 记录调用的交易及参数，返回固定结果
+type fakeContract struct {
+	result []byte
+	name   string
+	args   []string
+}
+
+func (f *fakeContract) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	f.name, f.args = name, args
+	return f.result, nil
+}
+
+func (f *fakeContract) SubmitTransactionWithTransient(name string, transient map[string][]byte, args ...string) ([]byte, error) {
+	return f.SubmitTransaction(name, args...)
+}
+
+func (f *fakeContract) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	return f.SubmitTransaction(name, args...)
+}
+
+// newTestMethodRouter 按main.go的方式注册测试方法路由，以fake替代调用者的合约
+func newTestMethodRouter(contract transactor) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	api := r.Group("/api/v1", func(c *gin.Context) {
+		c.Set(contractKey, contract)
+		c.Next()
+	})
+
+	handler := NewTestMethodHandler()
+	api.GET("/test-methods", handler.GetAllTestMethods)
+	api.GET("/test-methods/*name", handler.GetTestMethod)
+	api.PUT("/test-methods/*name", handler.SetTestMethod)
+
+	return r
+}
+
+func TestTestMethodNameWithSlash(t *testing.T) {
+	tests := []struct {
+		method      string
+		body        string
+		transaction string
+	}{
+		{http.MethodGet, "", "ReadTestMethod"},
+		{http.MethodPut, `{"temperature": {"min": 18, "max": 22, "unit": "℃"}}`, "SetTestMethod"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			contract := &fakeContract{result: []byte(`{"name": "GB/T 13992"}`)}
+			r := newTestMethodRouter(contract)
+
+			req := httptest.NewRequest(tt.method, "/api/v1/test-methods/GB/T%2013992", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+			}
+			if contract.name != tt.transaction || len(contract.args) == 0 || contract.args[0] != "GB/T 13992" {
+				t.Errorf("called %s%q, want %s with name %q", contract.name, contract.args, tt.transaction, "GB/T 13992")
+			}
+		})
+	}
+}
+
+func TestTestMethodNameRequired(t *testing.T) {
+	contract := &fakeContract{}
+	r := newTestMethodRouter(contract)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/test-methods/", strings.NewReader(`{}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if contract.name != "" {
+		t.Errorf("unexpected transaction %s", contract.name)
+	}
+}
+
+func TestGetAllTestMethodsRoute(t *testing.T) {
+	contract := &fakeContract{result: []byte(`[]`)}
+	r := newTestMethodRouter(contract)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/test-methods", nil))
+
+	if w.Code != http.StatusOK || contract.name != "GetAllTestMethods" {
+		t.Errorf("status = %d, transaction = %s, want %d GetAllTestMethods", w.Code, contract.name, http.StatusOK)
+	}
+}
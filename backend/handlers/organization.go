@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"certificate-backend/models"
+)
+
+type InspectionOrganizationHandler struct{}
+
+func NewInspectionOrganizationHandler() *InspectionOrganizationHandler {
+	return &InspectionOrganizationHandler{}
+}
+
+// CreateInspectionOrganization 登记检验机构及其认可范围，需组织管理员身份
+func (h *InspectionOrganizationHandler) CreateInspectionOrganization(c *gin.Context) {
+	var req models.CreateInspectionOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	org := models.InspectionOrganization{
+		ID:                req.ID,
+		Name:              req.Name,
+		AccreditationBody: req.AccreditationBody,
+		AccreditationNo:   req.AccreditationNo,
+		AccreditedFrom:    req.AccreditedFrom,
+		AccreditedUntil:   req.AccreditedUntil,
+		Scope:             req.Scope,
+	}
+
+	orgData, err := json.Marshal(org)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal inspection organization data"})
+		return
+	}
+
+	_, err = userContract(c).SubmitTransaction("CreateInspectionOrganization", req.ID, string(orgData))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to create inspection organization: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":                  "Inspection organization created successfully",
+		"inspectionOrganizationId": req.ID,
+	})
+}
+
+// GetInspectionOrganization 获取检验机构详情
+func (h *InspectionOrganizationHandler) GetInspectionOrganization(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := userContract(c).EvaluateTransaction("ReadInspectionOrganization", id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Inspection organization not found"})
+		return
+	}
+
+	var org models.InspectionOrganization
+	if err := json.Unmarshal(result, &org); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal inspection organization data"})
+		return
+	}
+
+	c.JSON(http.StatusOK, org)
+}
+
+// UpdateInspectionOrganization 更新检验机构的认可信息，需组织管理员身份
+func (h *InspectionOrganizationHandler) UpdateInspectionOrganization(c *gin.Context) {
+	id := c.Param("id")
+	var req models.UpdateInspectionOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 获取现有检验机构
+	existingResult, err := userContract(c).EvaluateTransaction("ReadInspectionOrganization", id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Inspection organization not found"})
+		return
+	}
+
+	var org models.InspectionOrganization
+	if err := json.Unmarshal(existingResult, &org); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal existing inspection organization"})
+		return
+	}
+
+	// 只更新提供的字段
+	if req.Name != "" {
+		org.Name = req.Name
+	}
+	if req.AccreditationBody != "" {
+		org.AccreditationBody = req.AccreditationBody
+	}
+	if req.AccreditationNo != "" {
+		org.AccreditationNo = req.AccreditationNo
+	}
+	if req.AccreditedFrom != "" {
+		org.AccreditedFrom = req.AccreditedFrom
+	}
+	if req.AccreditedUntil != "" {
+		org.AccreditedUntil = req.AccreditedUntil
+	}
+	if req.Scope != nil {
+		org.Scope = req.Scope
+	}
+
+	orgData, err := json.Marshal(org)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal inspection organization data"})
+		return
+	}
+
+	_, err = userContract(c).SubmitTransaction("UpdateInspectionOrganization", id, string(orgData))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": fmt.Sprintf("Failed to update inspection organization: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Inspection organization updated successfully"})
+}
+
+// GetAllInspectionOrganizations 获取全部检验机构
+func (h *InspectionOrganizationHandler) GetAllInspectionOrganizations(c *gin.Context) {
+	result, err := userContract(c).EvaluateTransaction("GetAllInspectionOrganizations")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to get inspection organizations: %v", err)})
+		return
+	}
+
+	orgs := []models.InspectionOrganization{}
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &orgs); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal inspection organization data"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, orgs)
+}
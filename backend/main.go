@@ -35,19 +35,75 @@ func main() {
 	})
 
 	// 初始化处理器
-	handler := handlers.NewCertificateHandler(fabricClient)
+	handler := handlers.NewCertificateHandler()
+	equipmentHandler := handlers.NewEquipmentHandler()
+	inspectorHandler := handlers.NewInspectorHandler()
+	organizationHandler := handlers.NewInspectionOrganizationHandler()
+	customerHandler := handlers.NewCustomerHandler()
+	testMethodHandler := handlers.NewTestMethodHandler()
 
 	// API路由
-	api := r.Group("/api/v1")
+	// 所有接口均需认证，交易以调用者本人的身份提交
+	api := r.Group("/api/v1", handlers.Authenticate(fabricClient))
 	{
 		// 证书相关路由
 		api.POST("/certificates", handler.CreateCertificate)
+		api.POST("/certificates/expire", handler.ExpireCertificates)
+		api.POST("/certificates/verify", handler.VerifyCertificate)
+		api.GET("/certificates/by-number/:no", handler.GetCertificateByNumber)
+		api.GET("/certificates/by-hash/:hash", handler.GetCertificateByHash)
 		api.GET("/certificates/:id", handler.GetCertificate)
 		api.PUT("/certificates/:id", handler.UpdateCertificate)
+		api.POST("/certificates/:id/submit", handler.SubmitForReview)
+		api.POST("/certificates/:id/review", handler.ReviewCertificate)
+		api.POST("/certificates/:id/approve", handler.ApproveCertificate)
 		api.POST("/certificates/:id/issue", handler.IssueCertificate)
+		api.POST("/certificates/:id/suspend", handler.SuspendCertificate)
+		api.POST("/certificates/:id/reinstate", handler.ReinstateCertificate)
 		api.POST("/certificates/:id/revoke", handler.RevokeCertificate)
+		api.POST("/certificates/:id/reissue", handler.ReissueCertificate)
 		api.GET("/certificates/:id/history", handler.GetCertificateHistory)
+		api.GET("/certificates/:id/versions", handler.GetCertificateVersions)
+		api.GET("/certificates/:id/traceability", handler.GetTraceabilityChain)
+		api.GET("/certificates/:id/impact", handler.GetCertificateImpact)
+		api.POST("/certificates/:id/recall", handler.RecallAffectedCertificates)
 		api.GET("/certificates", handler.QueryCertificates)
+		api.GET("/instruments/:serial/certificates", handler.GetInstrumentCertificates)
+
+		// 测量设备相关路由
+		api.POST("/equipment", equipmentHandler.CreateEquipment)
+		api.GET("/equipment", equipmentHandler.GetAllEquipment)
+		api.GET("/equipment/:id", equipmentHandler.GetEquipment)
+		api.PUT("/equipment/:id", equipmentHandler.UpdateEquipment)
+		api.GET("/equipment/:id/impact", equipmentHandler.GetEquipmentImpact)
+		api.POST("/equipment/:id/recall", equipmentHandler.RecallAffectedCertificates)
+
+		// 检验员相关路由
+		api.POST("/inspectors", inspectorHandler.CreateInspector)
+		api.GET("/inspectors", inspectorHandler.GetAllInspectors)
+		api.GET("/inspectors/:id", inspectorHandler.GetInspector)
+		api.PUT("/inspectors/:id", inspectorHandler.UpdateInspector)
+		api.DELETE("/inspectors/:id", inspectorHandler.DeleteInspector)
+
+		// 检验机构相关路由
+		api.POST("/inspection-orgs", organizationHandler.CreateInspectionOrganization)
+		api.GET("/inspection-orgs", organizationHandler.GetAllInspectionOrganizations)
+		api.GET("/inspection-orgs/:id", organizationHandler.GetInspectionOrganization)
+		api.PUT("/inspection-orgs/:id", organizationHandler.UpdateInspectionOrganization)
+
+		// 客户相关路由
+		api.POST("/customers", customerHandler.CreateCustomer)
+		api.GET("/customers", customerHandler.GetAllCustomers)
+		api.GET("/customers/by-credit-code/:code", customerHandler.GetCustomerByCreditCode)
+		api.GET("/customers/:id", customerHandler.GetCustomer)
+		api.PUT("/customers/:id", customerHandler.UpdateCustomer)
+		api.GET("/customers/:id/certificates", customerHandler.GetCustomerCertificates)
+
+		// 测试方法相关路由
+		api.GET("/test-methods", testMethodHandler.GetAllTestMethods)
+		// 方法名称为标准编号，可能含有"/"
+		api.GET("/test-methods/*name", testMethodHandler.GetTestMethod)
+		api.PUT("/test-methods/*name", testMethodHandler.SetTestMethod)
 	}
 
 	// 启动服务器
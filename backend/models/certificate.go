@@ -3,21 +3,51 @@ package models
 type Certificate struct {
 	ID               string            `json:"id"`
 	CertificateNo    string            `json:"certificateNo"`
+	CustomerID       string            `json:"customerId"`
 	TestUnit         string            `json:"testUnit"`
+	OwnerMSP         string            `json:"ownerMsp"`
+	Item             *CalibratedItem   `json:"item,omitempty"`
 	TestDate         string            `json:"testDate"`
-	TestData         []TestDataItem    `json:"testData"`
+	Environment      *EnvironmentalConditions `json:"environment,omitempty"`
+	TestData         []TestDataItem    `json:"testData,omitempty"`
+	TestDataHash     string            `json:"testDataHash"`
+	Salt             string            `json:"salt,omitempty"`
+	EquipmentIDs     []string          `json:"equipmentIds,omitempty"`
 	InspectionOrg    string            `json:"inspectionOrg"`
 	Inspector        string            `json:"inspector"`
 	Status           string            `json:"status"`
+	SuspensionReason string            `json:"suspensionReason"`
+	FlaggedReason    string            `json:"flaggedReason"`
 	IssuedDate       string            `json:"issuedDate"`
+	IssuedBy         string            `json:"issuedBy"`
 	ValidUntil       string            `json:"validUntil"`
 	Hash             string            `json:"hash"`
+	HashAlgorithm    string            `json:"hashAlgorithm"`
+	InspectorSignature *InspectorSignature `json:"inspectorSignature,omitempty"`
+	AccreditationStatus   string   `json:"accreditationStatus"`
+	AccreditationNo       string   `json:"accreditationNo"`
+	AccreditationProblems []string `json:"accreditationProblems,omitempty"`
 	CreatedBy        string            `json:"createdBy"`
+	SubmittedBy      string            `json:"submittedBy"`
+	InspectorIdentity string           `json:"inspectorIdentity"`
+	ReviewedBy       string            `json:"reviewedBy"`
+	ReviewComments   string            `json:"reviewComments"`
+	ApprovedBy       string            `json:"approvedBy"`
+	Supersedes       string            `json:"supersedes"`
+	SupersededBy     string            `json:"supersededBy"`
 	CreatedAt        string            `json:"createdAt"`
 	UpdatedAt        string            `json:"updatedAt"`
 	TraceHistory     []TraceRecord     `json:"traceHistory"`
 }
 
+type CalibratedItem struct {
+	Manufacturer string `json:"manufacturer" binding:"required"`
+	Model        string `json:"model" binding:"required"`
+	SerialNumber string `json:"serialNumber" binding:"required"`
+	AssetTag     string `json:"assetTag"`
+	Description  string `json:"description"`
+}
+
 type TestDataItem struct {
 	Parameter     string  `json:"parameter"`
 	MeasuredValue float64 `json:"measuredValue"`
@@ -25,48 +55,141 @@ type TestDataItem struct {
 	Uncertainty   float64 `json:"uncertainty"`
 	Method        string  `json:"method"`
 	Equipment     string  `json:"equipment"`
+	EquipmentID   string  `json:"equipmentId"`
+	Environment   *EnvironmentalConditions `json:"environment,omitempty"`
+}
+
+type InspectorSignature struct {
+	Algorithm         string `json:"algorithm"`
+	Signature         string `json:"signature"`
+	SignerCertificate string `json:"signerCertificate"`
+	Signer            string `json:"signer"`
+	SignerMSP         string `json:"signerMsp"`
 }
 
 type TraceRecord struct {
-	Timestamp string `json:"timestamp"`
-	Action    string `json:"action"`
-	Operator  string `json:"operator"`
-	Details   string `json:"details"`
+	Timestamp   string `json:"timestamp"`
+	Action      string `json:"action"`
+	Operator    string `json:"operator"`
+	OperatorMSP string `json:"operatorMsp"`
+	Details     string `json:"details"`
 }
 
 type CreateCertificateRequest struct {
 	CertificateNo string         `json:"certificateNo" binding:"required"`
-	TestUnit      string         `json:"testUnit" binding:"required"`
+	CustomerID    string         `json:"customerId" binding:"required"`
+	Item          *CalibratedItem `json:"item" binding:"required"`
 	TestDate      string         `json:"testDate" binding:"required"`
+	Environment   *EnvironmentalConditions `json:"environment"`
 	TestData      []TestDataItem `json:"testData" binding:"required"`
 	InspectionOrg string         `json:"inspectionOrg" binding:"required"`
 	Inspector     string         `json:"inspector" binding:"required"`
 	ValidUntil    string         `json:"validUntil" binding:"required"`
-	CreatedBy     string         `json:"createdBy" binding:"required"`
+	HashAlgorithm string         `json:"hashAlgorithm" binding:"omitempty,oneof=SHA-256 SM3"`
 }
 
 type UpdateCertificateRequest struct {
 	CertificateNo string         `json:"certificateNo"`
-	TestUnit      string         `json:"testUnit"`
+	CustomerID    string         `json:"customerId"`
+	Item          *CalibratedItem `json:"item"`
 	TestDate      string         `json:"testDate"`
+	Environment   *EnvironmentalConditions `json:"environment"`
 	TestData      []TestDataItem `json:"testData"`
 	InspectionOrg string         `json:"inspectionOrg"`
 	Inspector     string         `json:"inspector"`
 	ValidUntil    string         `json:"validUntil"`
-	Operator      string         `json:"operator" binding:"required"`
+	HashAlgorithm string         `json:"hashAlgorithm" binding:"omitempty,oneof=SHA-256 SM3"`
+}
+
+type CertificateHashLookup struct {
+	Certificate Certificate `json:"certificate"`
+	Current     bool        `json:"current"`
+}
+
+type VerifyCertificateRequest struct {
+	ID          string       `json:"id"`
+	Hash        string       `json:"hash"`
+	Certificate *Certificate `json:"certificate"`
+}
+
+type VerificationResult struct {
+	CertificateID       string `json:"certificateId"`
+	CertificateNo       string `json:"certificateNo"`
+	Hash                string `json:"hash"`
+	HashAlgorithm       string `json:"hashAlgorithm"`
+	Match               bool   `json:"match"`
+	Valid               bool   `json:"valid"`
+	Status              string `json:"status"`
+	InspectionOrg       string `json:"inspectionOrg"`
+	AccreditationStatus string `json:"accreditationStatus"`
+	AccreditationNo     string `json:"accreditationNo"`
+	IssuedBy            string `json:"issuedBy"`
+	IssuedDate          string `json:"issuedDate"`
+}
+
+type ReviewCertificateRequest struct {
+	Approved *bool  `json:"approved" binding:"required"`
+	Comments string `json:"comments"`
 }
 
 type IssueCertificateRequest struct {
-	Operator string `json:"operator" binding:"required"`
+	Signature         string `json:"signature" binding:"required"`
+	SignerCertificate string `json:"signerCertificate" binding:"required"`
+}
+
+type SuspendCertificateRequest struct {
+	ReasonCode string `json:"reasonCode" binding:"required,oneof=complaint investigation equipment_issue data_review other"`
+	Details    string `json:"details"`
+}
+
+type ReinstateCertificateRequest struct {
+	Details string `json:"details"`
+}
+
+type ReissueCertificateRequest struct {
+	CertificateNo string `json:"certificateNo" binding:"required"`
 }
 
 type RevokeCertificateRequest struct {
-	Operator string `json:"operator" binding:"required"`
-	Reason   string `json:"reason" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
 }
 
 type QueryCertificatesRequest struct {
-	TestUnit      string `form:"testUnit"`
-	Status        string `form:"status"`
-	InspectionOrg string `form:"inspectionOrg"`
+	CustomerID     string `form:"customerId"`
+	TestUnit       string `form:"testUnit"`
+	Status         string `form:"status"`
+	InspectionOrg  string `form:"inspectionOrg"`
+	Inspector      string `form:"inspector"`
+	TestDateFrom   string `form:"testDateFrom"`
+	TestDateTo     string `form:"testDateTo"`
+	IssuedDateFrom string `form:"issuedDateFrom"`
+	IssuedDateTo   string `form:"issuedDateTo"`
+	ValidUntilFrom string `form:"validUntilFrom"`
+	ValidUntilTo   string `form:"validUntilTo"`
+	SortBy         string `form:"sortBy" binding:"omitempty,oneof=testDate issuedDate validUntil certificateNo"`
+	SortOrder      string `form:"sortOrder" binding:"omitempty,oneof=asc desc"`
+	PageSize       int32  `form:"pageSize" binding:"min=0"`
+	Bookmark       string `form:"bookmark"`
+}
+
+type CertificateFilter struct {
+	CustomerID     string `json:"customerId,omitempty"`
+	TestUnit       string `json:"testUnit,omitempty"`
+	Status         string `json:"status,omitempty"`
+	InspectionOrg  string `json:"inspectionOrg,omitempty"`
+	Inspector      string `json:"inspector,omitempty"`
+	TestDateFrom   string `json:"testDateFrom,omitempty"`
+	TestDateTo     string `json:"testDateTo,omitempty"`
+	IssuedDateFrom string `json:"issuedDateFrom,omitempty"`
+	IssuedDateTo   string `json:"issuedDateTo,omitempty"`
+	ValidUntilFrom string `json:"validUntilFrom,omitempty"`
+	ValidUntilTo   string `json:"validUntilTo,omitempty"`
+	SortBy         string `json:"sortBy,omitempty"`
+	SortOrder      string `json:"sortOrder,omitempty"`
+}
+
+type PaginatedCertificates struct {
+	Records             []Certificate `json:"records"`
+	FetchedRecordsCount int32         `json:"fetchedRecordsCount"`
+	Bookmark            string        `json:"bookmark"`
 }
\ No newline at end of file
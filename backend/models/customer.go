@@ -0,0 +1,24 @@
+package models
+
+type Customer struct {
+	ID         string   `json:"id"`
+	CreditCode string   `json:"creditCode"`
+	Name       string   `json:"name"`
+	Aliases    []string `json:"aliases,omitempty"`
+	ContactMSP string   `json:"contactMsp"`
+	CreatedAt  string   `json:"createdAt"`
+	UpdatedAt  string   `json:"updatedAt"`
+}
+
+type CreateCustomerRequest struct {
+	CreditCode string   `json:"creditCode" binding:"required,len=18"`
+	Name       string   `json:"name" binding:"required"`
+	Aliases    []string `json:"aliases"`
+	ContactMSP string   `json:"contactMsp" binding:"required"`
+}
+
+type UpdateCustomerRequest struct {
+	CreditCode string   `json:"creditCode" binding:"omitempty,len=18"`
+	Name       string   `json:"name"`
+	Aliases    []string `json:"aliases"`
+}
@@ -0,0 +1,35 @@
+package models
+
+type EnvironmentReading struct {
+	Value       float64 `json:"value"`
+	Unit        string  `json:"unit" binding:"required"`
+	Uncertainty float64 `json:"uncertainty" binding:"gte=0"`
+}
+
+type EnvironmentalConditions struct {
+	Temperature *EnvironmentReading `json:"temperature" binding:"required"`
+	Humidity    *EnvironmentReading `json:"humidity,omitempty"`
+	Pressure    *EnvironmentReading `json:"pressure,omitempty"`
+}
+
+type EnvironmentLimit struct {
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Unit string  `json:"unit" binding:"required"`
+}
+
+type TestMethod struct {
+	Name        string            `json:"name"`
+	MSPID       string            `json:"mspId"`
+	Temperature *EnvironmentLimit `json:"temperature,omitempty"`
+	Humidity    *EnvironmentLimit `json:"humidity,omitempty"`
+	Pressure    *EnvironmentLimit `json:"pressure,omitempty"`
+	CreatedAt   string            `json:"createdAt"`
+	UpdatedAt   string            `json:"updatedAt"`
+}
+
+type SetTestMethodRequest struct {
+	Temperature *EnvironmentLimit `json:"temperature"`
+	Humidity    *EnvironmentLimit `json:"humidity"`
+	Pressure    *EnvironmentLimit `json:"pressure"`
+}
@@ -0,0 +1,54 @@
+package models
+
+type Equipment struct {
+	ID                       string              `json:"id"`
+	Model                    string              `json:"model"`
+	SerialNumber             string              `json:"serialNumber"`
+	OwnerOrg                 string              `json:"ownerOrg"`
+	CalibrationCertificateID string              `json:"calibrationCertificateId"`
+	IsReferenceStandard      bool                `json:"isReferenceStandard"`
+	CalibrationHistory       []CalibrationRecord `json:"calibrationHistory,omitempty"`
+	CreatedAt                string              `json:"createdAt"`
+	UpdatedAt                string              `json:"updatedAt"`
+}
+
+type CalibrationRecord struct {
+	CertificateID string `json:"certificateId"`
+	RegisteredAt  string `json:"registeredAt"`
+}
+
+type CreateEquipmentRequest struct {
+	Model                    string `json:"model" binding:"required"`
+	SerialNumber             string `json:"serialNumber" binding:"required"`
+	OwnerOrg                 string `json:"ownerOrg" binding:"required"`
+	CalibrationCertificateID string `json:"calibrationCertificateId"`
+	IsReferenceStandard      bool   `json:"isReferenceStandard"`
+}
+
+type UpdateEquipmentRequest struct {
+	Model                    string `json:"model"`
+	SerialNumber             string `json:"serialNumber"`
+	OwnerOrg                 string `json:"ownerOrg"`
+	CalibrationCertificateID string `json:"calibrationCertificateId"`
+	IsReferenceStandard      *bool  `json:"isReferenceStandard"`
+}
+
+type TraceabilityNode struct {
+	CertificateID string                `json:"certificateId"`
+	CertificateNo string                `json:"certificateNo"`
+	Status        string                `json:"status"`
+	ValidUntil    string                `json:"validUntil"`
+	Traceable     bool                  `json:"traceable"`
+	Equipment     []*EquipmentTraceNode `json:"equipment,omitempty"`
+}
+
+type EquipmentTraceNode struct {
+	EquipmentID         string            `json:"equipmentId"`
+	Model               string            `json:"model"`
+	SerialNumber        string            `json:"serialNumber"`
+	OwnerOrg            string            `json:"ownerOrg"`
+	IsReferenceStandard bool              `json:"isReferenceStandard"`
+	Traceable           bool              `json:"traceable"`
+	Calibration         *TraceabilityNode `json:"calibration,omitempty"`
+	Problem             string            `json:"problem,omitempty"`
+}
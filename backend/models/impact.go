@@ -0,0 +1,31 @@
+package models
+
+type ImpactNode struct {
+	CertificateID string                 `json:"certificateId"`
+	CertificateNo string                 `json:"certificateNo"`
+	Status        string                 `json:"status"`
+	TestDate      string                 `json:"testDate"`
+	OwnerMSP      string                 `json:"ownerMsp"`
+	Equipment     []*ImpactEquipmentNode `json:"equipment,omitempty"`
+}
+
+type ImpactEquipmentNode struct {
+	EquipmentID  string        `json:"equipmentId"`
+	Model        string        `json:"model"`
+	SerialNumber string        `json:"serialNumber"`
+	OwnerOrg     string        `json:"ownerOrg"`
+	Certificates []*ImpactNode `json:"certificates,omitempty"`
+}
+
+type ImpactReport struct {
+	SourceType             string               `json:"sourceType"`
+	SourceID               string               `json:"sourceId"`
+	Certificate            *ImpactNode          `json:"certificate,omitempty"`
+	Equipment              *ImpactEquipmentNode `json:"equipment,omitempty"`
+	AffectedCertificateIDs []string             `json:"affectedCertificateIds"`
+}
+
+type RecallCertificatesRequest struct {
+	Action string `json:"action" binding:"required,oneof=suspend flag"`
+	Reason string `json:"reason" binding:"required"`
+}
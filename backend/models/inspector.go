@@ -0,0 +1,33 @@
+package models
+
+type Inspector struct {
+	ID                   string   `json:"id"`
+	Name                 string   `json:"name"`
+	Identity             string   `json:"identity"`
+	MSPID                string   `json:"mspId"`
+	AuthorizedParameters []string `json:"authorizedParameters"`
+	AuthorizedMethods    []string `json:"authorizedMethods,omitempty"`
+	QualifiedFrom        string   `json:"qualifiedFrom"`
+	QualifiedUntil       string   `json:"qualifiedUntil"`
+	CreatedAt            string   `json:"createdAt"`
+	UpdatedAt            string   `json:"updatedAt"`
+}
+
+type CreateInspectorRequest struct {
+	ID                   string   `json:"id" binding:"required"`
+	Name                 string   `json:"name" binding:"required"`
+	Identity             string   `json:"identity" binding:"required"`
+	AuthorizedParameters []string `json:"authorizedParameters" binding:"required,min=1"`
+	AuthorizedMethods    []string `json:"authorizedMethods"`
+	QualifiedFrom        string   `json:"qualifiedFrom" binding:"required"`
+	QualifiedUntil       string   `json:"qualifiedUntil" binding:"required"`
+}
+
+type UpdateInspectorRequest struct {
+	Name                 string   `json:"name"`
+	Identity             string   `json:"identity"`
+	AuthorizedParameters []string `json:"authorizedParameters"`
+	AuthorizedMethods    []string `json:"authorizedMethods"`
+	QualifiedFrom        string   `json:"qualifiedFrom"`
+	QualifiedUntil       string   `json:"qualifiedUntil"`
+}
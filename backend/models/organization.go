@@ -0,0 +1,42 @@
+package models
+
+type AccreditationScope struct {
+	Parameter string  `json:"parameter" binding:"required"`
+	Unit      string  `json:"unit"`
+	Method    string  `json:"method"`
+	RangeMin  float64 `json:"rangeMin"`
+	RangeMax  float64 `json:"rangeMax"`
+	CMC       float64 `json:"cmc"`
+}
+
+type InspectionOrganization struct {
+	ID                string               `json:"id"`
+	Name              string               `json:"name"`
+	MSPID             string               `json:"mspId"`
+	AccreditationBody string               `json:"accreditationBody"`
+	AccreditationNo   string               `json:"accreditationNo"`
+	AccreditedFrom    string               `json:"accreditedFrom"`
+	AccreditedUntil   string               `json:"accreditedUntil"`
+	Scope             []AccreditationScope `json:"scope"`
+	CreatedAt         string               `json:"createdAt"`
+	UpdatedAt         string               `json:"updatedAt"`
+}
+
+type CreateInspectionOrganizationRequest struct {
+	ID                string               `json:"id" binding:"required"`
+	Name              string               `json:"name" binding:"required"`
+	AccreditationBody string               `json:"accreditationBody" binding:"required"`
+	AccreditationNo   string               `json:"accreditationNo" binding:"required"`
+	AccreditedFrom    string               `json:"accreditedFrom" binding:"required"`
+	AccreditedUntil   string               `json:"accreditedUntil" binding:"required"`
+	Scope             []AccreditationScope `json:"scope" binding:"required,min=1,dive"`
+}
+
+type UpdateInspectionOrganizationRequest struct {
+	Name              string               `json:"name"`
+	AccreditationBody string               `json:"accreditationBody"`
+	AccreditationNo   string               `json:"accreditationNo"`
+	AccreditedFrom    string               `json:"accreditedFrom"`
+	AccreditedUntil   string               `json:"accreditedUntil"`
+	Scope             []AccreditationScope `json:"scope" binding:"omitempty,dive"`
+}
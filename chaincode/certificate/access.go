@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// certOrgMSP 检验机构组织，唯一允许创建、修改、签发和撤销证书的组织
+const certOrgMSP = "CertOrgMSP"
+
+// roleAttribute 客户端X.509证书中表示检验机构内部角色的属性
+const roleAttribute = "cert.role"
+
+const (
+	roleInspector = "inspector" // 检验员
+	roleReviewer  = "reviewer"  // 核验员
+	roleApprover  = "approver"  // 批准人
+)
+
+// clientIdentity 调用者身份
+type clientIdentity struct {
+	MSPID   string
+	Subject string
+	Role    string
+}
+
+// getClientIdentity 从交易上下文读取调用者的MSP ID和证书主题
+func getClientIdentity(ctx contractapi.TransactionContextInterface) (*clientIdentity, error) {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+
+	x509Cert, err := ctx.GetClientIdentity().GetX509Certificate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client certificate: %v", err)
+	}
+	if x509Cert == nil {
+		return nil, fmt.Errorf("client identity has no X.509 certificate")
+	}
+
+	return &clientIdentity{
+		MSPID:   mspID,
+		Subject: x509Cert.Subject.String(),
+	}, nil
+}
+
+// permissionDenied 构造权限不足错误
+func permissionDenied(format string, args ...interface{}) error {
+	return fmt.Errorf("permission denied: "+format, args...)
+}
+
+// requireCertOrg 仅允许检验机构的客户端修改证书
+func requireCertOrg(ctx contractapi.TransactionContextInterface) (*clientIdentity, error) {
+	identity, err := getClientIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if identity.MSPID != certOrgMSP {
+		return nil, permissionDenied("client from %s is not allowed to modify certificates", identity.MSPID)
+	}
+
+	return identity, nil
+}
+
+// requireRole 要求调用者属于检验机构，且cert.role属性为指定角色之一
+func requireRole(ctx contractapi.TransactionContextInterface, action string, roles ...string) (*clientIdentity, error) {
+	identity, err := requireCertOrg(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	role, found, err := ctx.GetClientIdentity().GetAttributeValue(roleAttribute)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client attribute %s: %v", roleAttribute, err)
+	}
+	if !found {
+		return nil, permissionDenied("client %s has no %s attribute and cannot %s", identity.Subject, roleAttribute, action)
+	}
+
+	for _, allowed := range roles {
+		if role == allowed {
+			identity.Role = role
+			return identity, nil
+		}
+	}
+
+	return nil, permissionDenied("role %q cannot %s, requires %s", role, action, strings.Join(roles, " or "))
+}
+
+// canReadCertificate 检验机构可读取全部证书，其他组织只能读取发给自己的证书
+func canReadCertificate(identity *clientIdentity, cert *Certificate) bool {
+	if identity.MSPID == certOrgMSP {
+		return true
+	}
+
+	return cert.OwnerMSP != "" && cert.OwnerMSP == identity.MSPID
+}
+
+// filterReadableCertificates 过滤掉调用者无权读取的证书
+func filterReadableCertificates(ctx contractapi.TransactionContextInterface, certificates []*Certificate) ([]*Certificate, error) {
+	identity, err := getClientIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var readable []*Certificate
+	for _, cert := range certificates {
+		if canReadCertificate(identity, cert) {
+			readable = append(readable, cert)
+		}
+	}
+
+	return readable, nil
+}
+
+// newTraceRecord 使用调用者的真实身份构造溯源记录
+func newTraceRecord(identity *clientIdentity, timestamp string, action string, details string) TraceRecord {
+	return TraceRecord{
+		Timestamp:   timestamp,
+		Action:      action,
+		Operator:    identity.Subject,
+		OperatorMSP: identity.MSPID,
+		Details:     details,
+	}
+}
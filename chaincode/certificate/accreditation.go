@@ -0,0 +1,308 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// inspectionOrgDocType 检验机构文档类型
+const inspectionOrgDocType = "inspectionOrg"
+
+// 证书的认可状态，签发时根据检验机构的认可范围确定
+const (
+	accreditationAccredited    = "accredited"
+	accreditationNonAccredited = "non-accredited"
+)
+
+// AccreditationScope 认可范围中的一项：参数、测量范围和最佳测量能力（CMC）
+type AccreditationScope struct {
+	Parameter string  `json:"parameter"` // 测试参数
+	Unit      string  `json:"unit"`      // 单位，测量范围和CMC均以此单位表示
+	Method    string  `json:"method"`    // 测试方法，为空时不限制
+	RangeMin  float64 `json:"rangeMin"`  // 测量范围下限
+	RangeMax  float64 `json:"rangeMax"`  // 测量范围上限
+	CMC       float64 `json:"cmc"`       // 最佳测量能力，证书声明的不确定度不得小于此值
+}
+
+// InspectionOrganization 登记的检验机构及其认可信息，证书的InspectionOrg字段为检验机构ID
+type InspectionOrganization struct {
+	DocType           string               `json:"docType"` // 文档类型，固定为inspectionOrg
+	ID                string               `json:"id"`
+	Name              string               `json:"name"`
+	MSPID             string               `json:"mspId"`             // 所属组织MSP，由登记的管理员确定
+	AccreditationBody string               `json:"accreditationBody"` // 认可机构，如CNAS
+	AccreditationNo   string               `json:"accreditationNo"`   // 认可证书编号
+	AccreditedFrom    string               `json:"accreditedFrom"`    // 认可有效期起
+	AccreditedUntil   string               `json:"accreditedUntil"`   // 认可有效期至
+	Scope             []AccreditationScope `json:"scope"`             // 认可范围
+	CreatedAt         string               `json:"createdAt"`
+	UpdatedAt         string               `json:"updatedAt"`
+}
+
+// inspectionOrgKey 返回检验机构的复合键
+func inspectionOrgKey(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(inspectionOrgDocType, []string{id})
+	if err != nil {
+		return "", fmt.Errorf("failed to create inspection organisation key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readInspectionOrg 从账本读取检验机构
+func readInspectionOrg(ctx contractapi.TransactionContextInterface, id string) (*InspectionOrganization, error) {
+	key, err := inspectionOrgKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	orgJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inspection organisation %s: %v", id, err)
+	}
+	if orgJSON == nil {
+		return nil, fmt.Errorf("inspection organisation %s does not exist", id)
+	}
+
+	var org InspectionOrganization
+	if err := json.Unmarshal(orgJSON, &org); err != nil {
+		return nil, err
+	}
+
+	return &org, nil
+}
+
+// putInspectionOrg 将检验机构写入账本
+func putInspectionOrg(ctx contractapi.TransactionContextInterface, org *InspectionOrganization) error {
+	key, err := inspectionOrgKey(ctx, org.ID)
+	if err != nil {
+		return err
+	}
+
+	orgJSON, err := json.Marshal(org)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(key, orgJSON)
+}
+
+// parseAccreditation 解析认可有效期，返回生效和失效时刻
+func parseAccreditation(org *InspectionOrganization) (time.Time, time.Time, error) {
+	from, err := time.Parse(dateLayout, org.AccreditedFrom)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid accreditedFrom %q: expected %s", org.AccreditedFrom, dateLayout)
+	}
+
+	until, err := parseValidUntil(org.AccreditedUntil)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid accreditedUntil %q: %v", org.AccreditedUntil, err)
+	}
+	if !from.Before(until) {
+		return time.Time{}, time.Time{}, fmt.Errorf("accreditedFrom %s is after accreditedUntil %s", org.AccreditedFrom, org.AccreditedUntil)
+	}
+
+	return from, until, nil
+}
+
+// validateInspectionOrg 校验检验机构必填字段、认可有效期和认可范围
+func validateInspectionOrg(org *InspectionOrganization) error {
+	if org.Name == "" || org.AccreditationBody == "" || org.AccreditationNo == "" {
+		return fmt.Errorf("inspection organisation name, accreditation body and accreditation number are required")
+	}
+	if len(org.Scope) == 0 {
+		return fmt.Errorf("accreditation scope must contain at least one entry")
+	}
+
+	for i, scope := range org.Scope {
+		if scope.Parameter == "" {
+			return fmt.Errorf("accreditation scope %d: parameter is required", i)
+		}
+		if scope.RangeMin > scope.RangeMax {
+			return fmt.Errorf("accreditation scope %d: rangeMin is greater than rangeMax", i)
+		}
+		if scope.CMC < 0 {
+			return fmt.Errorf("accreditation scope %d: cmc must not be negative", i)
+		}
+	}
+
+	_, _, err := parseAccreditation(org)
+	return err
+}
+
+// CreateInspectionOrganization 登记检验机构及其认可范围，仅检验机构组织的管理员可调用
+func (s *SmartContract) CreateInspectionOrganization(ctx contractapi.TransactionContextInterface, id string, orgData string) error {
+	identity, err := requireCertOrgAdmin(ctx, "register inspection organisations")
+	if err != nil {
+		return err
+	}
+
+	if _, err := readInspectionOrg(ctx, id); err == nil {
+		return fmt.Errorf("inspection organisation %s already exists", id)
+	}
+
+	var org InspectionOrganization
+	if err := json.Unmarshal([]byte(orgData), &org); err != nil {
+		return fmt.Errorf("failed to unmarshal inspection organisation data: %v", err)
+	}
+	if err := validateInspectionOrg(&org); err != nil {
+		return err
+	}
+
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	org.DocType = inspectionOrgDocType
+	org.ID = id
+	org.MSPID = identity.MSPID
+	org.CreatedAt = now
+	org.UpdatedAt = now
+
+	return putInspectionOrg(ctx, &org)
+}
+
+// UpdateInspectionOrganization 更新检验机构的认可信息，如认可复评后的有效期和范围，仅检验机构组织的管理员可调用
+func (s *SmartContract) UpdateInspectionOrganization(ctx contractapi.TransactionContextInterface, id string, orgData string) error {
+	if _, err := requireCertOrgAdmin(ctx, "update inspection organisations"); err != nil {
+		return err
+	}
+
+	org, err := readInspectionOrg(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var updated InspectionOrganization
+	if err := json.Unmarshal([]byte(orgData), &updated); err != nil {
+		return fmt.Errorf("failed to unmarshal inspection organisation data: %v", err)
+	}
+	if err := validateInspectionOrg(&updated); err != nil {
+		return err
+	}
+
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	updated.DocType = inspectionOrgDocType
+	updated.ID = org.ID
+	updated.MSPID = org.MSPID
+	updated.CreatedAt = org.CreatedAt
+	updated.UpdatedAt = now
+
+	return putInspectionOrg(ctx, &updated)
+}
+
+// ReadInspectionOrganization 读取检验机构
+func (s *SmartContract) ReadInspectionOrganization(ctx contractapi.TransactionContextInterface, id string) (*InspectionOrganization, error) {
+	return readInspectionOrg(ctx, id)
+}
+
+// GetAllInspectionOrganizations 获取全部检验机构
+func (s *SmartContract) GetAllInspectionOrganizations(ctx contractapi.TransactionContextInterface) ([]*InspectionOrganization, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(inspectionOrgDocType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	orgs := []*InspectionOrganization{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var org InspectionOrganization
+		if err := json.Unmarshal(queryResponse.Value, &org); err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, &org)
+	}
+
+	return orgs, nil
+}
+
+// checkInspectionOrg 确认证书的检验机构已由签发组织登记
+func checkInspectionOrg(ctx contractapi.TransactionContextInterface, cert *Certificate) (*InspectionOrganization, error) {
+	if cert.InspectionOrg == "" {
+		return nil, fmt.Errorf("inspection organisation is required")
+	}
+
+	org, err := readInspectionOrg(ctx, cert.InspectionOrg)
+	if err != nil {
+		return nil, fmt.Errorf("inspection organisation %s is not registered", cert.InspectionOrg)
+	}
+	if org.MSPID != certOrgMSP {
+		return nil, fmt.Errorf("inspection organisation %s is registered by %s, not by the issuing organisation", org.ID, org.MSPID)
+	}
+
+	return org, nil
+}
+
+// scopeProblem 检查测试数据是否在认可范围内，在范围内时返回空字符串。
+// 参数、单位、方法和测量范围均匹配的认可项中，任一项的CMC不大于声明的不确定度即可
+func scopeProblem(org *InspectionOrganization, item TestDataItem) string {
+	inRange := false
+	minCMC := 0.0
+	for _, scope := range org.Scope {
+		if scope.Parameter != item.Parameter || (scope.Unit != "" && scope.Unit != item.Unit) || (scope.Method != "" && scope.Method != item.Method) {
+			continue
+		}
+		if item.MeasuredValue < scope.RangeMin || item.MeasuredValue > scope.RangeMax {
+			continue
+		}
+
+		if item.Uncertainty >= scope.CMC {
+			return ""
+		}
+		if !inRange || scope.CMC < minCMC {
+			minCMC = scope.CMC
+		}
+		inRange = true
+	}
+
+	if inRange {
+		return fmt.Sprintf("%s uncertainty %s %s is smaller than CMC %s %s", item.Parameter,
+			formatCanonicalFloat(item.Uncertainty), item.Unit, formatCanonicalFloat(minCMC), item.Unit)
+	}
+
+	return fmt.Sprintf("%s %s %s is outside the accredited scope", item.Parameter, formatCanonicalFloat(item.MeasuredValue), item.Unit)
+}
+
+// accreditationProblems 检查检验机构在签发时刻的认可是否有效，以及测试数据是否均在认可范围内，
+// 返回全部问题，没有问题时证书可作为认可证书签发
+func accreditationProblems(org *InspectionOrganization, testDataJSON []byte, at time.Time) ([]string, error) {
+	var problems []string
+
+	from, until, err := parseAccreditation(org)
+	if err != nil {
+		return nil, err
+	}
+	if at.Before(from) || !at.Before(until) {
+		problems = append(problems, fmt.Sprintf("accreditation %s of %s is not valid on %s (valid %s to %s)",
+			org.AccreditationNo, org.ID, formatTimestamp(at), org.AccreditedFrom, org.AccreditedUntil))
+	}
+
+	if testDataJSON == nil {
+		return problems, nil
+	}
+
+	var testData []TestDataItem
+	if err := json.Unmarshal(testDataJSON, &testData); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal test data: %v", err)
+	}
+	for _, item := range testData {
+		if problem := scopeProblem(org, item); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+
+	return problems, nil
+}
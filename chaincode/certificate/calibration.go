@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// CalibrationRecord 设备校准证书的登记记录
+type CalibrationRecord struct {
+	CertificateID string `json:"certificateId"`
+	RegisteredAt  string `json:"registeredAt"`
+}
+
+// recordCalibration 设备登记了新的校准证书时追加校准记录
+func recordCalibration(equipment *Equipment, now string) {
+	if equipment.CalibrationCertificateID == "" {
+		return
+	}
+
+	history := equipment.CalibrationHistory
+	if len(history) > 0 && history[len(history)-1].CertificateID == equipment.CalibrationCertificateID {
+		return
+	}
+
+	equipment.CalibrationHistory = append(history, CalibrationRecord{
+		CertificateID: equipment.CalibrationCertificateID,
+		RegisteredAt:  now,
+	})
+}
+
+// calibrationCertificateIDs 返回设备使用过的全部校准证书ID，包括校准记录之前登记的当前证书
+func calibrationCertificateIDs(equipment *Equipment) []string {
+	var ids []string
+	for _, record := range equipment.CalibrationHistory {
+		ids = append(ids, record.CertificateID)
+	}
+
+	if equipment.CalibrationCertificateID != "" {
+		found := false
+		for _, id := range ids {
+			if id == equipment.CalibrationCertificateID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ids = append(ids, equipment.CalibrationCertificateID)
+		}
+	}
+
+	return ids
+}
+
+// parseTestDate 解析测试日期，返回测试的起止时刻。仅含日期时覆盖当天（UTC）
+func parseTestDate(testDate string) (time.Time, time.Time, error) {
+	if t, err := time.Parse(dateLayout, testDate); err == nil {
+		return t, t.AddDate(0, 0, 1), nil
+	}
+
+	t, err := time.Parse(timestampLayout, testDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid testDate %q: expected %s or RFC3339", testDate, dateLayout)
+	}
+
+	return t.UTC(), t.UTC(), nil
+}
+
+// calibrationProblem 检查校准证书在测试期间是否有效，有效时返回空字符串。
+// 校准证书须在测试前签发、测试时未过有效期，且未被撤销、暂停或换发。
+// 换发的原证书可能因内容有误被替代，设备须登记换发后的证书
+func calibrationProblem(calibration *Certificate, testStart time.Time, testEnd time.Time) string {
+	switch calibration.Status {
+	case statusIssued, statusExpired:
+	case statusSuperseded:
+		return fmt.Sprintf("calibration certificate %s is superseded by %s", calibration.ID, calibration.SupersededBy)
+	case statusRevoked, statusSuspended:
+		return fmt.Sprintf("calibration certificate %s is %s", calibration.ID, calibration.Status)
+	default:
+		return fmt.Sprintf("calibration certificate %s has not been issued", calibration.ID)
+	}
+
+	issuedAt, err := time.Parse(timestampLayout, calibration.IssuedDate)
+	if err != nil || !issuedAt.Before(testEnd) {
+		return fmt.Sprintf("calibration certificate %s was issued after the test", calibration.ID)
+	}
+
+	expiresAt, err := parseValidUntil(calibration.ValidUntil)
+	if err != nil || !testStart.Before(expiresAt) {
+		return fmt.Sprintf("calibration certificate %s expired on %s", calibration.ID, calibration.ValidUntil)
+	}
+
+	return ""
+}
+
+// calibrationForTest 选取设备在测试期间有效的校准证书，签发检查和溯源链均按此选取。
+// 任一校准证书在测试期间有效即可，否则返回最近一次校准及其问题，设备从未校准时证书为nil
+func (s *SmartContract) calibrationForTest(ctx contractapi.TransactionContextInterface, equipment *Equipment, testStart time.Time, testEnd time.Time) (*Certificate, string, error) {
+	var calibration *Certificate
+	problem := "no calibration certificate"
+	for _, calibrationID := range calibrationCertificateIDs(equipment) {
+		var err error
+		calibration, err = s.readCertificate(ctx, calibrationID)
+		if err != nil {
+			return nil, "", err
+		}
+
+		problem = calibrationProblem(calibration, testStart, testEnd)
+		if problem == "" {
+			break
+		}
+	}
+
+	return calibration, problem, nil
+}
+
+// checkEquipmentCalibration 确认证书引用的每台设备在测试日期都有有效且未撤销的校准证书，
+// 否则返回列出全部问题设备的错误。基准或最高计量标准是溯源链终点，不做检查。
+func (s *SmartContract) checkEquipmentCalibration(ctx contractapi.TransactionContextInterface, cert *Certificate) error {
+	if len(cert.EquipmentIDs) == 0 {
+		return nil
+	}
+
+	testStart, testEnd, err := parseTestDate(cert.TestDate)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	for _, equipmentID := range cert.EquipmentIDs {
+		equipment, err := readEquipment(ctx, equipmentID)
+		if err != nil {
+			return err
+		}
+		if equipment.IsReferenceStandard {
+			continue
+		}
+
+		_, problem, err := s.calibrationForTest(ctx, equipment, testStart, testEnd)
+		if err != nil {
+			return err
+		}
+		if problem != "" {
+			problems = append(problems, fmt.Sprintf("%s (%s): %s", equipment.ID, equipment.SerialNumber, problem))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("certificate %s cannot be issued: equipment calibration not valid on %s: %s",
+			cert.ID, cert.TestDate, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
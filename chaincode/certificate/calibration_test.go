@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCalibrationProblem(t *testing.T) {
+	testStart, testEnd, err := parseTestDate("2025-03-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cert    Certificate
+		problem string // 期望问题描述包含的内容，为空时期望有效
+	}{
+		{"issued", Certificate{Status: statusIssued}, ""},
+		{"expired since", Certificate{Status: statusExpired}, ""},
+		{"superseded", Certificate{Status: statusSuperseded, SupersededBy: "CAL-002"}, "superseded by CAL-002"},
+		{"revoked", Certificate{Status: statusRevoked}, "revoked"},
+		{"suspended", Certificate{Status: statusSuspended}, "suspended"},
+		{"not issued", Certificate{Status: statusApproved}, "has not been issued"},
+		{"issued after test", Certificate{Status: statusIssued, IssuedDate: "2025-03-02T00:00:00Z"}, "issued after the test"},
+		{"expired before test", Certificate{Status: statusIssued, ValidUntil: "2025-02-28"}, "expired on 2025-02-28"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := tt.cert
+			cert.ID = "CAL-001"
+			if cert.IssuedDate == "" {
+				cert.IssuedDate = "2025-01-01T00:00:00Z"
+			}
+			if cert.ValidUntil == "" {
+				cert.ValidUntil = "2026-01-01"
+			}
+
+			problem := calibrationProblem(&cert, testStart, testEnd)
+			switch {
+			case tt.problem == "" && problem != "":
+				t.Errorf("calibrationProblem() = %q, want valid", problem)
+			case tt.problem != "" && !strings.Contains(problem, tt.problem):
+				t.Errorf("calibrationProblem() = %q, want %q", problem, tt.problem)
+			}
+		})
+	}
+}
@@ -3,28 +3,67 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
 )
 
 type SmartContract struct {
 	contractapi.Contract
 }
 
+// 证书状态，签发前需依次经过编制、提交核验、核验和批准
+const (
+	statusDraft      = "draft"
+	statusSubmitted  = "submitted"
+	statusReviewed   = "reviewed"
+	statusApproved   = "approved"
+	statusIssued     = "issued"
+	statusSuspended  = "suspended"
+	statusRevoked    = "revoked"
+	statusExpired    = "expired"
+	statusSuperseded = "superseded"
+)
+
 type Certificate struct {
+	DocType          string            `json:"docType"`         // 文档类型，固定为certificate
 	ID               string            `json:"id"`
 	CertificateNo    string            `json:"certificateNo"`
-	TestUnit         string            `json:"testUnit"`        // 送检单位
+	CustomerID       string            `json:"customerId"`      // 客户ID，须为已登记的客户
+	TestUnit         string            `json:"testUnit"`        // 送检单位，取自客户名称
+	OwnerMSP         string            `json:"ownerMsp"`        // 接收证书的组织MSP，取自客户
+	Item             *CalibratedItem   `json:"item,omitempty" metadata:",optional"` // 被校设备
 	TestDate         string            `json:"testDate"`        // 测试日期
-	TestData         []TestDataItem    `json:"testData"`        // 测试数据
-	InspectionOrg    string            `json:"inspectionOrg"`   // 检验机构
-	Inspector        string            `json:"inspector"`       // 检验员
-	Status           string            `json:"status"`          // 证书状态：draft, issued, revoked
+	Environment      *EnvironmentalConditions `json:"environment,omitempty" metadata:",optional"` // 测量时的环境条件
+	TestData         []TestDataItem    `json:"testData,omitempty" metadata:",optional"` // 测试数据，保存在私有数据集合中，公开账本上为空
+	TestDataHash     string            `json:"testDataHash"`    // 私有测试数据记录（含盐值）的SHA-256哈希
+	Salt             string            `json:"salt,omitempty" metadata:",optional"` // 测试数据盐值，计入内容哈希；保存在私有数据集合中，公开账本上为空
+	EquipmentIDs     []string          `json:"equipmentIds,omitempty" metadata:",optional"` // 测试数据引用的设备ID，公开用于溯源
+	InspectionOrg    string            `json:"inspectionOrg"`   // 检验机构ID，须为已登记的检验机构
+	Inspector        string            `json:"inspector"`       // 检验员ID，须为已登记的检验员
+	Status           string            `json:"status"`          // 证书状态：draft, submitted, reviewed, approved, issued, suspended, revoked, expired, superseded
+	SuspensionReason string            `json:"suspensionReason"` // 暂停原因代码，仅suspended状态有值
+	FlaggedReason    string            `json:"flaggedReason"`   // 存疑标记，上游计量标准或设备出现问题时由批量召回设置
 	IssuedDate       string            `json:"issuedDate"`      // 签发日期
+	IssuedBy         string            `json:"issuedBy"`        // 签发人
 	ValidUntil       string            `json:"validUntil"`      // 有效期至
 	Hash             string            `json:"hash"`            // 证书内容哈希
+	HashAlgorithm    string            `json:"hashAlgorithm"`   // 证书内容哈希算法：SHA-256或SM3
+	InspectorSignature *InspectorSignature `json:"inspectorSignature,omitempty" metadata:",optional"` // 检验员对内容哈希的签名，签发时提交
+	AccreditationStatus   string       `json:"accreditationStatus"` // 认可状态：accredited或non-accredited，签发时确定
+	AccreditationNo       string       `json:"accreditationNo"`     // 认可证书编号，仅认可证书有值
+	AccreditationProblems []string     `json:"accreditationProblems,omitempty" metadata:",optional"` // 作为非认可证书签发的原因
 	CreatedBy        string            `json:"createdBy"`       // 创建者
+	SubmittedBy      string            `json:"submittedBy"`     // 提交核验的检验员
+	InspectorIdentity string           `json:"inspectorIdentity"` // 提交核验时证书指定检验员的证书主题
+	ReviewedBy       string            `json:"reviewedBy"`      // 核验员
+	ReviewComments   string            `json:"reviewComments"`  // 核验意见
+	ApprovedBy       string            `json:"approvedBy"`      // 批准人
+	Supersedes       string            `json:"supersedes"`      // 本证书替代的原证书ID
+	SupersededBy     string            `json:"supersededBy"`    // 替代本证书的新证书ID
 	CreatedAt        string            `json:"createdAt"`       // 创建时间
 	UpdatedAt        string            `json:"updatedAt"`       // 更新时间
 	TraceHistory     []TraceRecord     `json:"traceHistory"`    // 溯源记录
@@ -37,13 +76,44 @@ type TestDataItem struct {
 	Uncertainty  float64 `json:"uncertainty"`  // 不确定度
 	Method       string  `json:"method"`       // 测试方法
 	Equipment    string  `json:"equipment"`    // 测试设备
+	EquipmentID  string  `json:"equipmentId"`  // 登记的测量设备ID
+	Environment  *EnvironmentalConditions `json:"environment,omitempty" metadata:",optional"` // 该项测量时的环境条件，为空时取证书的环境条件
 }
 
 type TraceRecord struct {
-	Timestamp string `json:"timestamp"`
-	Action    string `json:"action"`
-	Operator  string `json:"operator"`
-	Details   string `json:"details"`
+	Timestamp   string `json:"timestamp"`
+	Action      string `json:"action"`
+	Operator    string `json:"operator"`    // 操作者证书主题
+	OperatorMSP string `json:"operatorMsp"` // 操作者所属组织MSP
+	Details     string `json:"details"`
+}
+
+// timestampLayout 链码中所有时间字段统一使用的格式（UTC）
+const timestampLayout = time.RFC3339
+
+// formatTimestamp 将时间格式化为统一的UTC时间字符串
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(timestampLayout)
+}
+
+// getTxTime 获取交易时间戳，所有背书节点对同一交易得到相同结果
+func getTxTime(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	ts, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+
+	return time.Unix(ts.Seconds, int64(ts.Nanos)).UTC(), nil
+}
+
+// getTxTimestamp 获取格式化后的交易时间戳，替代time.Now()以保证写集确定性
+func getTxTimestamp(ctx contractapi.TransactionContextInterface) (string, error) {
+	txTime, err := getTxTime(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return formatTimestamp(txTime), nil
 }
 
 // InitLedger 初始化账本
@@ -54,6 +124,11 @@ func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface)
 
 // CreateCertificate 创建证书
 func (s *SmartContract) CreateCertificate(ctx contractapi.TransactionContextInterface, id string, certificateData string) error {
+	identity, err := requireCertOrg(ctx)
+	if err != nil {
+		return err
+	}
+
 	exists, err := s.CertificateExists(ctx, id)
 	if err != nil {
 		return err
@@ -68,93 +143,265 @@ func (s *SmartContract) CreateCertificate(ctx contractapi.TransactionContextInte
 		return fmt.Errorf("failed to unmarshal certificate data: %v", err)
 	}
 
+	if err := checkCertificateNoAvailable(ctx, cert.CertificateNo, id); err != nil {
+		return err
+	}
+
+	if err := setCertificateCustomer(ctx, &cert); err != nil {
+		return err
+	}
+	if err := validateItem(cert.Item); err != nil {
+		return err
+	}
+	if err := normalizeValidUntil(&cert.ValidUntil); err != nil {
+		return err
+	}
+	if len(cert.TestData) > 0 {
+		return fmt.Errorf("test data must be passed in the transient map under key %q", testDataTransientKey)
+	}
+
+	testDataJSON, err := getTransientTestData(ctx)
+	if err != nil {
+		return err
+	}
+	salt, err := getTransientSalt(ctx)
+	if err != nil {
+		return err
+	}
+	if err := validateTestDataEnvironment(&cert, testDataJSON); err != nil {
+		return err
+	}
+	if _, err := checkInspectorQualified(ctx, &cert, testDataJSON, time.Time{}); err != nil {
+		return err
+	}
+	if _, err := checkInspectionOrg(ctx, &cert); err != nil {
+		return err
+	}
+
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	cert.DocType = certificateDocType
 	cert.ID = id
-	cert.Status = "draft"
-	cert.CreatedAt = time.Now().Format(time.RFC3339)
-	cert.UpdatedAt = time.Now().Format(time.RFC3339)
+	cert.Status = statusDraft
+	cert.CreatedBy = identity.Subject
+	cert.CreatedAt = now
+	cert.UpdatedAt = now
+	cert.TraceHistory = nil
 
 	// 添加创建记录到溯源历史
-	traceRecord := TraceRecord{
-		Timestamp: time.Now().Format(time.RFC3339),
-		Action:    "CREATED",
-		Operator:  cert.CreatedBy,
-		Details:   "Certificate created",
-	}
+	traceRecord := newTraceRecord(identity, now, "CREATED", "Certificate created")
 	cert.TraceHistory = append(cert.TraceHistory, traceRecord)
 
-	certificateJSON, err := json.Marshal(cert)
-	if err != nil {
+	cert.IssuedBy = ""
+	cert.InspectorSignature = nil
+	cert.AccreditationStatus = ""
+	cert.AccreditationNo = ""
+	cert.AccreditationProblems = nil
+	if err := setCertificateEquipment(ctx, &cert, testDataJSON); err != nil {
+		return err
+	}
+	if err := setCertificateHash(&cert, salt, testDataJSON); err != nil {
 		return err
 	}
 
-	return ctx.GetStub().PutState(id, certificateJSON)
+	cert.TestDataHash = ""
+	if testDataJSON != nil {
+		if err := putPrivateTestData(ctx, &cert, salt, testDataJSON); err != nil {
+			return err
+		}
+	}
+
+	if err := putCertificate(ctx, &cert); err != nil {
+		return err
+	}
+
+	if err := putIndex(ctx, certNoIndex, cert.CertificateNo, id); err != nil {
+		return err
+	}
+	if serial := itemSerial(&cert); serial != "" {
+		if err := putIndex(ctx, itemSerialIndex, serial, id); err != nil {
+			return err
+		}
+	}
+	if err := putIndex(ctx, hashIndex, cert.Hash, id); err != nil {
+		return err
+	}
+
+	return emitCertificateEvent(ctx, eventCertificateCreated, &cert, now)
 }
 
-// IssueCertificate 签发证书
-func (s *SmartContract) IssueCertificate(ctx contractapi.TransactionContextInterface, id string, operator string) error {
-	cert, err := s.ReadCertificate(ctx, id)
+// IssueCertificate 签发证书，需附检验员对证书内容哈希的ECDSA签名（Base64）及其X.509证书（PEM）
+func (s *SmartContract) IssueCertificate(ctx contractapi.TransactionContextInterface, id string, signature string, signerCertificate string) error {
+	identity, err := requireRole(ctx, "issue certificates", roleApprover)
+	if err != nil {
+		return err
+	}
+
+	cert, err := s.readCertificate(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	if cert.Status != "draft" {
-		return fmt.Errorf("certificate %s is not in draft status", id)
+	if cert.Status != statusApproved {
+		return fmt.Errorf("certificate %s is not in approved status", id)
 	}
 
-	cert.Status = "issued"
-	cert.IssuedDate = time.Now().Format(time.RFC3339)
-	cert.UpdatedAt = time.Now().Format(time.RFC3339)
+	txTime, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	if isExpired(cert, txTime) {
+		return fmt.Errorf("certificate %s cannot be issued: valid until %s has passed", id, cert.ValidUntil)
+	}
+	if err := s.checkEquipmentCalibration(ctx, cert); err != nil {
+		return err
+	}
 
-	// 添加签发记录到溯源历史
-	traceRecord := TraceRecord{
-		Timestamp: time.Now().Format(time.RFC3339),
-		Action:    "ISSUED",
-		Operator:  operator,
-		Details:   "Certificate issued",
+	testDataJSON, err := getPrivateTestDataJSON(ctx, cert)
+	if err != nil {
+		return err
+	}
+	inspector, err := checkInspectorQualified(ctx, cert, testDataJSON, txTime)
+	if err != nil {
+		return err
+	}
+	if err := checkEnvironmentalConditions(ctx, cert, testDataJSON); err != nil {
+		return err
 	}
-	cert.TraceHistory = append(cert.TraceHistory, traceRecord)
 
-	certificateJSON, err := json.Marshal(cert)
+	// 签名者须属于签发组织，且为证书登记的检验员
+	inspectorSignature, err := s.verifyInspectorSignature(ctx, cert, identity.MSPID, signature, signerCertificate, txTime)
 	if err != nil {
 		return err
 	}
+	if inspectorSignature.Signer != inspector.Identity {
+		return fmt.Errorf("certificate %s must be signed by inspector %s (%s), not %s", id, inspector.ID, inspector.Identity, inspectorSignature.Signer)
+	}
+
+	// 超出认可范围或认可失效时作为非认可证书签发
+	org, err := checkInspectionOrg(ctx, cert)
+	if err != nil {
+		return err
+	}
+	problems, err := accreditationProblems(org, testDataJSON, txTime)
+	if err != nil {
+		return err
+	}
+
+	now := formatTimestamp(txTime)
+
+	cert.Status = statusIssued
+	cert.IssuedDate = now
+	cert.IssuedBy = identity.Subject
+	cert.InspectorSignature = inspectorSignature
+	cert.UpdatedAt = now
+
+	issueDetails := "Certificate issued"
+	if len(problems) == 0 {
+		cert.AccreditationStatus = accreditationAccredited
+		cert.AccreditationNo = org.AccreditationNo
+		cert.AccreditationProblems = nil
+	} else {
+		cert.AccreditationStatus = accreditationNonAccredited
+		cert.AccreditationNo = ""
+		cert.AccreditationProblems = problems
+		issueDetails = fmt.Sprintf("Certificate issued as non-accredited: %s", strings.Join(problems, "; "))
+	}
+
+	// 添加签发记录到溯源历史
+	traceRecord := newTraceRecord(identity, now, "ISSUED", issueDetails)
+	cert.TraceHistory = append(cert.TraceHistory, traceRecord)
+
+	if err := putCertificate(ctx, cert); err != nil {
+		return err
+	}
+	if err := putIndex(ctx, hashIndex, cert.Hash, id); err != nil {
+		return err
+	}
 
-	return ctx.GetStub().PutState(id, certificateJSON)
+	// 换发证书签发后，原证书被替代
+	if cert.Supersedes != "" {
+		if err := s.supersedeOriginal(ctx, identity, cert, now); err != nil {
+			return err
+		}
+	}
+
+	return emitCertificateEvent(ctx, eventCertificateIssued, cert, now)
 }
 
 // RevokeCertificate 撤销证书
-func (s *SmartContract) RevokeCertificate(ctx contractapi.TransactionContextInterface, id string, operator string, reason string) error {
-	cert, err := s.ReadCertificate(ctx, id)
+func (s *SmartContract) RevokeCertificate(ctx contractapi.TransactionContextInterface, id string, reason string) error {
+	identity, err := requireRole(ctx, "revoke certificates", roleApprover)
 	if err != nil {
 		return err
 	}
 
-	if cert.Status == "revoked" {
+	cert, err := s.readCertificate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if cert.Status == statusRevoked {
 		return fmt.Errorf("certificate %s is already revoked", id)
 	}
 
-	cert.Status = "revoked"
-	cert.UpdatedAt = time.Now().Format(time.RFC3339)
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
 
-	// 添加撤销记录到溯源历史
-	traceRecord := TraceRecord{
-		Timestamp: time.Now().Format(time.RFC3339),
-		Action:    "REVOKED",
-		Operator:  operator,
-		Details:   fmt.Sprintf("Certificate revoked. Reason: %s", reason),
+	// 未签发的换发证书被撤销时，解除原证书的替代关联以便重新换发
+	if cert.Supersedes != "" && cert.Status != statusIssued && cert.Status != statusSuspended {
+		if err := s.releaseOriginal(ctx, identity, cert, now); err != nil {
+			return err
+		}
 	}
+
+	cert.Status = statusRevoked
+	cert.SuspensionReason = ""
+	cert.UpdatedAt = now
+
+	// 添加撤销记录到溯源历史
+	traceRecord := newTraceRecord(identity, now, "REVOKED", fmt.Sprintf("Certificate revoked. Reason: %s", reason))
 	cert.TraceHistory = append(cert.TraceHistory, traceRecord)
 
-	certificateJSON, err := json.Marshal(cert)
-	if err != nil {
+	if err := putCertificate(ctx, cert); err != nil {
 		return err
 	}
 
-	return ctx.GetStub().PutState(id, certificateJSON)
+	return emitCertificateEvent(ctx, eventCertificateRevoked, cert, now)
 }
 
-// ReadCertificate 读取证书
+// ReadCertificate 读取证书（送检单位只能读取发给自己的证书）
 func (s *SmartContract) ReadCertificate(ctx contractapi.TransactionContextInterface, id string) (*Certificate, error) {
+	identity, err := getClientIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := s.readCertificate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canReadCertificate(identity, cert) {
+		return nil, permissionDenied("client from %s is not allowed to read certificate %s", identity.MSPID, id)
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	applyEffectiveStatus(cert, now)
+
+	return cert, nil
+}
+
+// readCertificate 从账本读取证书，不做访问控制，仅供链码内部使用
+func (s *SmartContract) readCertificate(ctx contractapi.TransactionContextInterface, id string) (*Certificate, error) {
 	certificateJSON, err := ctx.GetStub().GetState(id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read certificate %s: %v", id, err)
@@ -172,14 +419,29 @@ func (s *SmartContract) ReadCertificate(ctx contractapi.TransactionContextInterf
 	return &cert, nil
 }
 
+// putCertificate 将证书写入账本
+func putCertificate(ctx contractapi.TransactionContextInterface, cert *Certificate) error {
+	certificateJSON, err := json.Marshal(cert)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(cert.ID, certificateJSON)
+}
+
 // UpdateCertificate 更新证书（仅草稿状态允许）
-func (s *SmartContract) UpdateCertificate(ctx contractapi.TransactionContextInterface, id string, certificateData string, operator string) error {
-	cert, err := s.ReadCertificate(ctx, id)
+func (s *SmartContract) UpdateCertificate(ctx contractapi.TransactionContextInterface, id string, certificateData string) error {
+	identity, err := requireRole(ctx, "update certificates", roleInspector)
 	if err != nil {
 		return err
 	}
 
-	if cert.Status != "draft" {
+	cert, err := s.readCertificate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if cert.Status != statusDraft {
 		return fmt.Errorf("cannot update certificate %s: only draft certificates can be updated", id)
 	}
 
@@ -189,33 +451,140 @@ func (s *SmartContract) UpdateCertificate(ctx contractapi.TransactionContextInte
 		return fmt.Errorf("failed to unmarshal certificate data: %v", err)
 	}
 
+	if err := setCertificateCustomer(ctx, &updatedCert); err != nil {
+		return err
+	}
+	if err := validateItem(updatedCert.Item); err != nil {
+		return err
+	}
+	if err := normalizeValidUntil(&updatedCert.ValidUntil); err != nil {
+		return err
+	}
+	if len(updatedCert.TestData) > 0 {
+		return fmt.Errorf("test data must be passed in the transient map under key %q", testDataTransientKey)
+	}
+
+	// 未提供新测试数据时沿用原私有数据及其盐值，送检单位变更时需迁移到新的私有数据集合
+	testDataJSON, err := getTransientTestData(ctx)
+	if err != nil {
+		return err
+	}
+	salt, err := getTransientSalt(ctx)
+	if err != nil {
+		return err
+	}
+	if testDataJSON == nil {
+		record, err := readPrivateTestData(ctx, cert)
+		if err != nil {
+			return err
+		}
+		if record != nil {
+			testDataJSON = record.TestData
+			if salt == "" {
+				salt = record.Salt
+			}
+		}
+	}
+	if err := validateTestDataEnvironment(&updatedCert, testDataJSON); err != nil {
+		return err
+	}
+	if _, err := checkInspectorQualified(ctx, &updatedCert, testDataJSON, time.Time{}); err != nil {
+		return err
+	}
+	if _, err := checkInspectionOrg(ctx, &updatedCert); err != nil {
+		return err
+	}
+	if updatedCert.OwnerMSP != cert.OwnerMSP && cert.TestDataHash != "" {
+		if err := ctx.GetStub().DelPrivateData(testDataCollection(cert.OwnerMSP), id); err != nil {
+			return fmt.Errorf("failed to delete private test data for certificate %s: %v", id, err)
+		}
+	}
+
+	if updatedCert.CertificateNo != cert.CertificateNo {
+		if err := checkCertificateNoAvailable(ctx, updatedCert.CertificateNo, id); err != nil {
+			return err
+		}
+		if err := deleteIndex(ctx, certNoIndex, cert.CertificateNo, id); err != nil {
+			return err
+		}
+		if err := putIndex(ctx, certNoIndex, updatedCert.CertificateNo, id); err != nil {
+			return err
+		}
+	}
+	if oldSerial, newSerial := itemSerial(cert), itemSerial(&updatedCert); oldSerial != newSerial {
+		if oldSerial != "" {
+			if err := deleteIndex(ctx, itemSerialIndex, oldSerial, id); err != nil {
+				return err
+			}
+		}
+		if newSerial != "" {
+			if err := putIndex(ctx, itemSerialIndex, newSerial, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	// 保留原有的元数据
+	updatedCert.DocType = certificateDocType
 	updatedCert.ID = cert.ID
 	updatedCert.Status = cert.Status
+	updatedCert.SuspensionReason = cert.SuspensionReason
+	updatedCert.FlaggedReason = cert.FlaggedReason
 	updatedCert.CreatedBy = cert.CreatedBy
 	updatedCert.CreatedAt = cert.CreatedAt
-	updatedCert.UpdatedAt = time.Now().Format(time.RFC3339)
+	updatedCert.SubmittedBy = cert.SubmittedBy
+	updatedCert.ReviewedBy = cert.ReviewedBy
+	updatedCert.ReviewComments = cert.ReviewComments
+	updatedCert.ApprovedBy = cert.ApprovedBy
+	updatedCert.Supersedes = cert.Supersedes
+	updatedCert.SupersededBy = cert.SupersededBy
+	updatedCert.UpdatedAt = now
 	updatedCert.TraceHistory = cert.TraceHistory
 
 	// 添加更新记录到溯源历史
-	traceRecord := TraceRecord{
-		Timestamp: time.Now().Format(time.RFC3339),
-		Action:    "UPDATED",
-		Operator:  operator,
-		Details:   "Certificate updated",
-	}
+	traceRecord := newTraceRecord(identity, now, "UPDATED", "Certificate updated")
 	updatedCert.TraceHistory = append(updatedCert.TraceHistory, traceRecord)
 
-	certificateJSON, err := json.Marshal(updatedCert)
-	if err != nil {
+	updatedCert.IssuedBy = ""
+	updatedCert.InspectorSignature = nil
+	updatedCert.AccreditationStatus = ""
+	updatedCert.AccreditationNo = ""
+	updatedCert.AccreditationProblems = nil
+	if err := setCertificateEquipment(ctx, &updatedCert, testDataJSON); err != nil {
 		return err
 	}
+	if err := setCertificateHash(&updatedCert, salt, testDataJSON); err != nil {
+		return err
+	}
+
+	updatedCert.TestDataHash = ""
+	if testDataJSON != nil {
+		if err := putPrivateTestData(ctx, &updatedCert, salt, testDataJSON); err != nil {
+			return err
+		}
+	}
 
-	return ctx.GetStub().PutState(id, certificateJSON)
+	if err := putCertificate(ctx, &updatedCert); err != nil {
+		return err
+	}
+	if err := putIndex(ctx, hashIndex, updatedCert.Hash, id); err != nil {
+		return err
+	}
+
+	return emitCertificateEvent(ctx, eventCertificateUpdated, &updatedCert, now)
 }
 
 // GetCertificateHistory 获取证书历史记录
 func (s *SmartContract) GetCertificateHistory(ctx contractapi.TransactionContextInterface, id string) ([]HistoryQueryResult, error) {
+	if _, err := s.ReadCertificate(ctx, id); err != nil {
+		return nil, err
+	}
+
 	resultsIterator, err := ctx.GetStub().GetHistoryForKey(id)
 	if err != nil {
 		return nil, err
@@ -239,7 +608,7 @@ func (s *SmartContract) GetCertificateHistory(ctx contractapi.TransactionContext
 
 		record := HistoryQueryResult{
 			TxId:      response.TxId,
-			Timestamp: time.Unix(response.Timestamp.Seconds, int64(response.Timestamp.Nanos)).String(),
+			Timestamp: formatTimestamp(time.Unix(response.Timestamp.Seconds, int64(response.Timestamp.Nanos))),
 			IsDelete:  response.IsDelete,
 			Value:     cert,
 		}
@@ -256,22 +625,41 @@ type HistoryQueryResult struct {
 	Value     Certificate `json:"value"`
 }
 
+// PaginatedQueryResult 分页查询结果，Bookmark用于获取下一页
+type PaginatedQueryResult struct {
+	Records             []*Certificate `json:"records"`
+	FetchedRecordsCount int32          `json:"fetchedRecordsCount"`
+	Bookmark            string         `json:"bookmark"`
+}
+
 // QueryCertificatesByTestUnit 按送检单位查询证书
 func (s *SmartContract) QueryCertificatesByTestUnit(ctx contractapi.TransactionContextInterface, testUnit string) ([]*Certificate, error) {
-	queryString := fmt.Sprintf(`{"selector":{"testUnit":"%s"}}`, testUnit)
-	return s.getQueryResultForQueryString(ctx, queryString)
+	return s.queryCertificatesByFilter(ctx, &CertificateFilter{TestUnit: testUnit})
 }
 
 // QueryCertificatesByStatus 按状态查询证书
 func (s *SmartContract) QueryCertificatesByStatus(ctx contractapi.TransactionContextInterface, status string) ([]*Certificate, error) {
-	queryString := fmt.Sprintf(`{"selector":{"status":"%s"}}`, status)
-	return s.getQueryResultForQueryString(ctx, queryString)
+	return s.queryCertificatesByFilter(ctx, &CertificateFilter{Status: status})
 }
 
 // QueryCertificatesByInspectionOrg 按检验机构查询证书
 func (s *SmartContract) QueryCertificatesByInspectionOrg(ctx contractapi.TransactionContextInterface, inspectionOrg string) ([]*Certificate, error) {
-	queryString := fmt.Sprintf(`{"selector":{"inspectionOrg":"%s"}}`, inspectionOrg)
-	return s.getQueryResultForQueryString(ctx, queryString)
+	return s.queryCertificatesByFilter(ctx, &CertificateFilter{InspectionOrg: inspectionOrg})
+}
+
+// QueryCertificatesByTestUnitWithPagination 按送检单位分页查询证书
+func (s *SmartContract) QueryCertificatesByTestUnitWithPagination(ctx contractapi.TransactionContextInterface, testUnit string, pageSize int32, bookmark string) (*PaginatedQueryResult, error) {
+	return s.queryCertificatesByFilterWithPagination(ctx, &CertificateFilter{TestUnit: testUnit}, pageSize, bookmark)
+}
+
+// QueryCertificatesByStatusWithPagination 按状态分页查询证书
+func (s *SmartContract) QueryCertificatesByStatusWithPagination(ctx contractapi.TransactionContextInterface, status string, pageSize int32, bookmark string) (*PaginatedQueryResult, error) {
+	return s.queryCertificatesByFilterWithPagination(ctx, &CertificateFilter{Status: status}, pageSize, bookmark)
+}
+
+// QueryCertificatesByInspectionOrgWithPagination 按检验机构分页查询证书
+func (s *SmartContract) QueryCertificatesByInspectionOrgWithPagination(ctx contractapi.TransactionContextInterface, inspectionOrg string, pageSize int32, bookmark string) (*PaginatedQueryResult, error) {
+	return s.queryCertificatesByFilterWithPagination(ctx, &CertificateFilter{InspectionOrg: inspectionOrg}, pageSize, bookmark)
 }
 
 // getQueryResultForQueryString 执行富查询
@@ -282,6 +670,54 @@ func (s *SmartContract) getQueryResultForQueryString(ctx contractapi.Transaction
 	}
 	defer resultsIterator.Close()
 
+	return constructQueryResponseFromIterator(ctx, resultsIterator)
+}
+
+// getQueryResultForQueryStringWithPagination 执行分页富查询
+func (s *SmartContract) getQueryResultForQueryStringWithPagination(ctx contractapi.TransactionContextInterface, queryString string, pageSize int32, bookmark string) (*PaginatedQueryResult, error) {
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be positive")
+	}
+
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetQueryResultWithPagination(queryString, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	certificates, err := constructQueryResponseFromIterator(ctx, resultsIterator)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPaginatedQueryResult(certificates, responseMetadata), nil
+}
+
+// constructQueryResponseFromIterator 将查询迭代器转换为证书列表，过滤调用者无权读取的证书并计算有效状态
+func constructQueryResponseFromIterator(ctx contractapi.TransactionContextInterface, resultsIterator shim.StateQueryIteratorInterface) ([]*Certificate, error) {
+	certificates, err := readCertificatesFromIterator(resultsIterator)
+	if err != nil {
+		return nil, err
+	}
+
+	certificates, err = filterReadableCertificates(ctx, certificates)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, cert := range certificates {
+		applyEffectiveStatus(cert, now)
+	}
+
+	return certificates, nil
+}
+
+// readCertificatesFromIterator 将查询迭代器转换为账本中存储的原始证书列表
+func readCertificatesFromIterator(resultsIterator shim.StateQueryIteratorInterface) ([]*Certificate, error) {
 	var certificates []*Certificate
 	for resultsIterator.HasNext() {
 		queryResponse, err := resultsIterator.Next()
@@ -300,6 +736,19 @@ func (s *SmartContract) getQueryResultForQueryString(ctx contractapi.Transaction
 	return certificates, nil
 }
 
+// newPaginatedQueryResult 组装分页查询结果
+func newPaginatedQueryResult(certificates []*Certificate, responseMetadata *peer.QueryResponseMetadata) *PaginatedQueryResult {
+	if certificates == nil {
+		certificates = []*Certificate{}
+	}
+
+	return &PaginatedQueryResult{
+		Records:             certificates,
+		FetchedRecordsCount: responseMetadata.FetchedRecordsCount,
+		Bookmark:            responseMetadata.Bookmark,
+	}
+}
+
 // CertificateExists 检查证书是否存在
 func (s *SmartContract) CertificateExists(ctx contractapi.TransactionContextInterface, id string) (bool, error) {
 	certificateJSON, err := ctx.GetStub().GetState(id)
@@ -310,7 +759,8 @@ func (s *SmartContract) CertificateExists(ctx contractapi.TransactionContextInte
 	return certificateJSON != nil, nil
 }
 
-// GetAllCertificates 获取所有证书
+// GetAllCertificates 获取所有证书。证书以ID为简单键存储，检验员、设备、客户等其他文档和索引
+// 均以复合键存储，不出现在空范围的查询中
 func (s *SmartContract) GetAllCertificates(ctx contractapi.TransactionContextInterface) ([]*Certificate, error) {
 	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
 	if err != nil {
@@ -318,22 +768,27 @@ func (s *SmartContract) GetAllCertificates(ctx contractapi.TransactionContextInt
 	}
 	defer resultsIterator.Close()
 
-	var certificates []*Certificate
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, err
-		}
+	return constructQueryResponseFromIterator(ctx, resultsIterator)
+}
 
-		var cert Certificate
-		err = json.Unmarshal(queryResponse.Value, &cert)
-		if err != nil {
-			return nil, err
-		}
-		certificates = append(certificates, &cert)
+// GetAllCertificatesWithPagination 分页获取所有证书
+func (s *SmartContract) GetAllCertificatesWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*PaginatedQueryResult, error) {
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be positive")
 	}
 
-	return certificates, nil
+	resultsIterator, responseMetadata, err := ctx.GetStub().GetStateByRangeWithPagination("", "", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	certificates, err := constructQueryResponseFromIterator(ctx, resultsIterator)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPaginatedQueryResult(certificates, responseMetadata), nil
 }
 
 func main() {
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+
+	"github.com/emmansun/gmsm/sm2"
+	"github.com/emmansun/gmsm/sm3"
+)
+
+// 证书内容哈希算法
+const (
+	hashAlgorithmSHA256 = "SHA-256"
+	hashAlgorithmSM3    = "SM3" // GM/T 0004
+)
+
+// 检验员签名算法
+const (
+	signatureAlgorithmECDSA = "ECDSA"
+	signatureAlgorithmSM2   = "SM2" // GM/T 0003，使用默认用户标识
+)
+
+// hashAlgorithms 支持的证书内容哈希算法
+var hashAlgorithms = map[string]func() hash.Hash{
+	hashAlgorithmSHA256: sha256.New,
+	hashAlgorithmSM3:    sm3.New,
+}
+
+// signatureVerifier 使用公钥校验ASN.1 DER格式的签名，message为证书内容哈希
+type signatureVerifier func(publicKey *ecdsa.PublicKey, message []byte, signature []byte) bool
+
+// signatureAlgorithms 支持的签名算法
+var signatureAlgorithms = map[string]signatureVerifier{
+	signatureAlgorithmECDSA: ecdsa.VerifyASN1,
+	signatureAlgorithmSM2: func(publicKey *ecdsa.PublicKey, message []byte, signature []byte) bool {
+		return sm2.VerifyASN1WithSM2(publicKey, nil, message, signature)
+	},
+}
+
+// normalizeHashAlgorithm 校验哈希算法标识，未指定时为SHA-256（兼容早期证书）
+func normalizeHashAlgorithm(algorithm string) (string, error) {
+	if algorithm == "" {
+		return hashAlgorithmSHA256, nil
+	}
+	if _, ok := hashAlgorithms[algorithm]; !ok {
+		return "", fmt.Errorf("unsupported hash algorithm %q", algorithm)
+	}
+
+	return algorithm, nil
+}
+
+// digest 使用指定算法计算摘要
+func digest(algorithm string, data []byte) ([]byte, error) {
+	algorithm, err := normalizeHashAlgorithm(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	h := hashAlgorithms[algorithm]()
+	h.Write(data)
+	return h.Sum(nil), nil
+}
+
+// signatureAlgorithmOf 根据签名者公钥确定签名算法：SM2曲线使用SM2，其余ECDSA曲线使用ECDSA
+func signatureAlgorithmOf(publicKey interface{}) (string, *ecdsa.PublicKey, error) {
+	ecdsaKey, ok := publicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return "", nil, fmt.Errorf("signer certificate must contain an ECDSA or SM2 public key")
+	}
+	if sm2.IsSM2PublicKey(ecdsaKey) {
+		return signatureAlgorithmSM2, ecdsaKey, nil
+	}
+
+	return signatureAlgorithmECDSA, ecdsaKey, nil
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// customerDocType 客户文档类型
+const customerDocType = "customer"
+
+// creditCodeIndex 统一社会信用代码到客户ID的复合键索引名
+const creditCodeIndex = "creditCode~id"
+
+// customerNameIndex 客户名称及别名到客户ID的复合键索引名，用于按送检单位名称查询证书
+const customerNameIndex = "customerName~id"
+
+// creditCodeCharset 统一社会信用代码字符集（GB 32100-2015），字符在其中的位置即代码值
+const creditCodeCharset = "0123456789ABCDEFGHJKLMNPQRTUWXY"
+
+// creditCodeWeights 统一社会信用代码前17位的加权因子
+var creditCodeWeights = []int{1, 3, 9, 27, 19, 26, 16, 17, 20, 29, 25, 13, 8, 24, 10, 30, 28}
+
+// Customer 登记的客户（送检单位），证书的CustomerID字段为客户ID
+type Customer struct {
+	DocType    string   `json:"docType"` // 文档类型，固定为customer
+	ID         string   `json:"id"`
+	CreditCode string   `json:"creditCode"`                             // 统一社会信用代码
+	Name       string   `json:"name"`                                   // 名称，证书的送检单位取此值
+	Aliases    []string `json:"aliases,omitempty" metadata:",optional"` // 曾用名、简称等其他写法
+	ContactMSP string   `json:"contactMsp"`                             // 接收证书的组织MSP，创建后不可变更
+	CreatedAt  string   `json:"createdAt"`
+	UpdatedAt  string   `json:"updatedAt"`
+}
+
+// validateCreditCode 校验统一社会信用代码的格式和校验位
+func validateCreditCode(code string) error {
+	if len(code) != 18 {
+		return fmt.Errorf("invalid credit code %q: must be 18 characters", code)
+	}
+
+	sum := 0
+	for i := 0; i < 17; i++ {
+		value := strings.IndexByte(creditCodeCharset, code[i])
+		if value < 0 {
+			return fmt.Errorf("invalid credit code %q: illegal character %q", code, code[i])
+		}
+		sum += value * creditCodeWeights[i]
+	}
+
+	check := (31 - sum%31) % 31
+	if code[17] != creditCodeCharset[check] {
+		return fmt.Errorf("invalid credit code %q: check digit mismatch", code)
+	}
+
+	return nil
+}
+
+// customerKey 返回客户的复合键
+func customerKey(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(customerDocType, []string{id})
+	if err != nil {
+		return "", fmt.Errorf("failed to create customer key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readCustomer 从账本读取客户
+func readCustomer(ctx contractapi.TransactionContextInterface, id string) (*Customer, error) {
+	key, err := customerKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	customerJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read customer %s: %v", id, err)
+	}
+	if customerJSON == nil {
+		return nil, fmt.Errorf("customer %s does not exist", id)
+	}
+
+	var customer Customer
+	if err := json.Unmarshal(customerJSON, &customer); err != nil {
+		return nil, err
+	}
+
+	return &customer, nil
+}
+
+// putCustomer 将客户写入账本
+func putCustomer(ctx contractapi.TransactionContextInterface, customer *Customer) error {
+	key, err := customerKey(ctx, customer.ID)
+	if err != nil {
+		return err
+	}
+
+	customerJSON, err := json.Marshal(customer)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(key, customerJSON)
+}
+
+// customerNames 返回客户名称和别名，去除首尾空白和重复项
+func customerNames(customer *Customer) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, name := range append([]string{customer.Name}, customer.Aliases...) {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// putCustomerIndexes 写入客户的信用代码和名称索引
+func putCustomerIndexes(ctx contractapi.TransactionContextInterface, customer *Customer) error {
+	if err := putIndex(ctx, creditCodeIndex, customer.CreditCode, customer.ID); err != nil {
+		return err
+	}
+	for _, name := range customerNames(customer) {
+		if err := putIndex(ctx, customerNameIndex, name, customer.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteCustomerIndexes 删除客户的信用代码和名称索引
+func deleteCustomerIndexes(ctx contractapi.TransactionContextInterface, customer *Customer) error {
+	if err := deleteIndex(ctx, creditCodeIndex, customer.CreditCode, customer.ID); err != nil {
+		return err
+	}
+	for _, name := range customerNames(customer) {
+		if err := deleteIndex(ctx, customerNameIndex, name, customer.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateCustomer 校验客户必填字段，统一社会信用代码须有效且未被其他客户使用
+func validateCustomer(ctx contractapi.TransactionContextInterface, customer *Customer, id string) error {
+	if customer.Name == "" || customer.ContactMSP == "" {
+		return fmt.Errorf("customer name and contact MSP are required")
+	}
+	if !testDataCollectionMSPs[customer.ContactMSP] {
+		return fmt.Errorf("contact MSP %s has no test data collection %s", customer.ContactMSP, testDataCollection(customer.ContactMSP))
+	}
+	if err := validateCreditCode(customer.CreditCode); err != nil {
+		return err
+	}
+
+	ids, err := findIDsByIndex(ctx, creditCodeIndex, customer.CreditCode)
+	if err != nil {
+		return err
+	}
+	for _, existingID := range ids {
+		if existingID != id {
+			return fmt.Errorf("credit code %s already exists (customer %s)", customer.CreditCode, existingID)
+		}
+	}
+
+	return nil
+}
+
+// CreateCustomer 登记客户
+func (s *SmartContract) CreateCustomer(ctx contractapi.TransactionContextInterface, id string, customerData string) error {
+	if _, err := requireCertOrg(ctx); err != nil {
+		return err
+	}
+
+	if _, err := readCustomer(ctx, id); err == nil {
+		return fmt.Errorf("customer %s already exists", id)
+	}
+
+	var customer Customer
+	if err := json.Unmarshal([]byte(customerData), &customer); err != nil {
+		return fmt.Errorf("failed to unmarshal customer data: %v", err)
+	}
+	if err := validateCustomer(ctx, &customer, id); err != nil {
+		return err
+	}
+
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	customer.DocType = customerDocType
+	customer.ID = id
+	customer.CreatedAt = now
+	customer.UpdatedAt = now
+
+	if err := putCustomer(ctx, &customer); err != nil {
+		return err
+	}
+
+	return putCustomerIndexes(ctx, &customer)
+}
+
+// UpdateCustomer 更新客户名称、别名和信用代码。接收证书的组织决定证书的读取权限和私有数据集合，不可变更
+func (s *SmartContract) UpdateCustomer(ctx contractapi.TransactionContextInterface, id string, customerData string) error {
+	if _, err := requireCertOrg(ctx); err != nil {
+		return err
+	}
+
+	customer, err := readCustomer(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var updated Customer
+	if err := json.Unmarshal([]byte(customerData), &updated); err != nil {
+		return fmt.Errorf("failed to unmarshal customer data: %v", err)
+	}
+	if updated.ContactMSP != customer.ContactMSP {
+		return fmt.Errorf("contact MSP of customer %s cannot be changed", id)
+	}
+	if err := validateCustomer(ctx, &updated, id); err != nil {
+		return err
+	}
+
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	updated.DocType = customerDocType
+	updated.ID = customer.ID
+	updated.CreatedAt = customer.CreatedAt
+	updated.UpdatedAt = now
+
+	if err := deleteCustomerIndexes(ctx, customer); err != nil {
+		return err
+	}
+	if err := putCustomer(ctx, &updated); err != nil {
+		return err
+	}
+
+	return putCustomerIndexes(ctx, &updated)
+}
+
+// ReadCustomer 读取客户
+func (s *SmartContract) ReadCustomer(ctx contractapi.TransactionContextInterface, id string) (*Customer, error) {
+	return readCustomer(ctx, id)
+}
+
+// ReadCustomerByCreditCode 按统一社会信用代码读取客户
+func (s *SmartContract) ReadCustomerByCreditCode(ctx contractapi.TransactionContextInterface, creditCode string) (*Customer, error) {
+	ids, err := findIDsByIndex(ctx, creditCodeIndex, creditCode)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("customer with credit code %s does not exist", creditCode)
+	}
+
+	return readCustomer(ctx, ids[0])
+}
+
+// GetAllCustomers 获取全部客户
+func (s *SmartContract) GetAllCustomers(ctx contractapi.TransactionContextInterface) ([]*Customer, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(customerDocType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	customers := []*Customer{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var customer Customer
+		if err := json.Unmarshal(queryResponse.Value, &customer); err != nil {
+			return nil, err
+		}
+		customers = append(customers, &customer)
+	}
+
+	return customers, nil
+}
+
+// QueryCertificatesByCustomer 按客户ID查询证书
+func (s *SmartContract) QueryCertificatesByCustomer(ctx contractapi.TransactionContextInterface, customerID string) ([]*Certificate, error) {
+	return s.queryCertificatesByFilter(ctx, &CertificateFilter{CustomerID: customerID})
+}
+
+// setCertificateCustomer 根据证书引用的客户设置送检单位和接收证书的组织
+func setCertificateCustomer(ctx contractapi.TransactionContextInterface, cert *Certificate) error {
+	if cert.CustomerID == "" {
+		return fmt.Errorf("customer ID is required")
+	}
+
+	customer, err := readCustomer(ctx, cert.CustomerID)
+	if err != nil {
+		return err
+	}
+	// 登记时已校验，此处防止集合配置变更后仍向不存在的集合写入测试数据
+	if !testDataCollectionMSPs[customer.ContactMSP] {
+		return fmt.Errorf("contact MSP %s of customer %s has no test data collection %s", customer.ContactMSP, customer.ID, testDataCollection(customer.ContactMSP))
+	}
+
+	cert.TestUnit = customer.Name
+	cert.OwnerMSP = customer.ContactMSP
+
+	return nil
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// testMethodDocType 测试方法文档类型
+const testMethodDocType = "testMethod"
+
+// EnvironmentReading 一项环境条件的测量结果
+type EnvironmentReading struct {
+	Value       float64 `json:"value"`
+	Unit        string  `json:"unit"`
+	Uncertainty float64 `json:"uncertainty"`
+}
+
+// EnvironmentalConditions 测量时的环境条件，温度必须记录，湿度和压力未测量时为空
+type EnvironmentalConditions struct {
+	Temperature *EnvironmentReading `json:"temperature"`                             // 温度
+	Humidity    *EnvironmentReading `json:"humidity,omitempty" metadata:",optional"` // 相对湿度
+	Pressure    *EnvironmentReading `json:"pressure,omitempty" metadata:",optional"` // 大气压力
+}
+
+// EnvironmentLimit 测试方法对一项环境条件的要求
+type EnvironmentLimit struct {
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Unit string  `json:"unit"`
+}
+
+// TestMethod 登记的测试方法及其环境条件要求，测试数据的Method字段为方法名称
+type TestMethod struct {
+	DocType     string            `json:"docType"` // 文档类型，固定为testMethod
+	Name        string            `json:"name"`
+	MSPID       string            `json:"mspId"` // 登记方法的组织MSP，签发时只采用签发组织登记的方法
+	Temperature *EnvironmentLimit `json:"temperature,omitempty" metadata:",optional"`
+	Humidity    *EnvironmentLimit `json:"humidity,omitempty" metadata:",optional"`
+	Pressure    *EnvironmentLimit `json:"pressure,omitempty" metadata:",optional"`
+	CreatedAt   string            `json:"createdAt"`
+	UpdatedAt   string            `json:"updatedAt"`
+}
+
+// environmentQuantity 环境条件的一项及其在方法中的要求
+type environmentQuantity struct {
+	name    string
+	reading *EnvironmentReading
+	limit   *EnvironmentLimit
+}
+
+// environmentQuantities 按温度、湿度、压力的顺序列出环境条件和方法要求，conditions和method均可为空
+func environmentQuantities(conditions *EnvironmentalConditions, method *TestMethod) []environmentQuantity {
+	if conditions == nil {
+		conditions = &EnvironmentalConditions{}
+	}
+	if method == nil {
+		method = &TestMethod{}
+	}
+
+	return []environmentQuantity{
+		{"temperature", conditions.Temperature, method.Temperature},
+		{"humidity", conditions.Humidity, method.Humidity},
+		{"pressure", conditions.Pressure, method.Pressure},
+	}
+}
+
+// validateEnvironment 校验环境条件的单位和不确定度，未提供时不校验
+func validateEnvironment(conditions *EnvironmentalConditions) error {
+	if conditions == nil {
+		return nil
+	}
+	if conditions.Temperature == nil {
+		return fmt.Errorf("temperature is required")
+	}
+
+	for _, quantity := range environmentQuantities(conditions, nil) {
+		if quantity.reading == nil {
+			continue
+		}
+		if quantity.reading.Unit == "" {
+			return fmt.Errorf("%s unit is required", quantity.name)
+		}
+		if quantity.reading.Uncertainty < 0 {
+			return fmt.Errorf("%s uncertainty must not be negative", quantity.name)
+		}
+	}
+
+	return nil
+}
+
+// validateTestDataEnvironment 校验证书和各测试数据的环境条件
+func validateTestDataEnvironment(cert *Certificate, testDataJSON []byte) error {
+	if err := validateEnvironment(cert.Environment); err != nil {
+		return fmt.Errorf("invalid environmental conditions: %v", err)
+	}
+	if testDataJSON == nil {
+		return nil
+	}
+
+	var testData []TestDataItem
+	if err := json.Unmarshal(testDataJSON, &testData); err != nil {
+		return fmt.Errorf("failed to unmarshal test data of certificate %s: %v", cert.ID, err)
+	}
+	for _, item := range testData {
+		if err := validateEnvironment(item.Environment); err != nil {
+			return fmt.Errorf("invalid environmental conditions of %s: %v", item.Parameter, err)
+		}
+	}
+
+	return nil
+}
+
+// canonicalEnvironment 环境条件的规范化表示，未记录环境条件时为null，未测量的项为null
+func canonicalEnvironment(conditions *EnvironmentalConditions) interface{} {
+	if conditions == nil {
+		return nil
+	}
+
+	canonical := map[string]interface{}{}
+	for _, quantity := range environmentQuantities(conditions, nil) {
+		if quantity.reading == nil {
+			canonical[quantity.name] = nil
+			continue
+		}
+		canonical[quantity.name] = map[string]string{
+			"value":       formatCanonicalFloat(quantity.reading.Value),
+			"unit":        quantity.reading.Unit,
+			"uncertainty": formatCanonicalFloat(quantity.reading.Uncertainty),
+		}
+	}
+
+	return canonical
+}
+
+// testMethodKey 返回测试方法的复合键
+func testMethodKey(ctx contractapi.TransactionContextInterface, name string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(testMethodDocType, []string{name})
+	if err != nil {
+		return "", fmt.Errorf("failed to create test method key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readTestMethod 从账本读取测试方法，未登记时返回nil
+func readTestMethod(ctx contractapi.TransactionContextInterface, name string) (*TestMethod, error) {
+	key, err := testMethodKey(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	methodJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test method %s: %v", name, err)
+	}
+	if methodJSON == nil {
+		return nil, nil
+	}
+
+	var method TestMethod
+	if err := json.Unmarshal(methodJSON, &method); err != nil {
+		return nil, err
+	}
+
+	return &method, nil
+}
+
+// SetTestMethod 登记或更新测试方法的环境条件要求，仅检验机构组织的管理员可调用
+func (s *SmartContract) SetTestMethod(ctx contractapi.TransactionContextInterface, name string, methodData string) error {
+	identity, err := requireCertOrgAdmin(ctx, "define test methods")
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("test method name is required")
+	}
+
+	var method TestMethod
+	if err := json.Unmarshal([]byte(methodData), &method); err != nil {
+		return fmt.Errorf("failed to unmarshal test method data: %v", err)
+	}
+	for _, quantity := range environmentQuantities(nil, &method) {
+		if quantity.limit == nil {
+			continue
+		}
+		if quantity.limit.Unit == "" {
+			return fmt.Errorf("%s limit unit is required", quantity.name)
+		}
+		if quantity.limit.Min > quantity.limit.Max {
+			return fmt.Errorf("%s limit min is greater than max", quantity.name)
+		}
+	}
+
+	existing, err := readTestMethod(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	method.DocType = testMethodDocType
+	method.Name = name
+	method.MSPID = identity.MSPID
+	method.CreatedAt = now
+	if existing != nil {
+		method.CreatedAt = existing.CreatedAt
+	}
+	method.UpdatedAt = now
+
+	key, err := testMethodKey(ctx, name)
+	if err != nil {
+		return err
+	}
+	methodJSON, err := json.Marshal(method)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(key, methodJSON)
+}
+
+// ReadTestMethod 读取测试方法
+func (s *SmartContract) ReadTestMethod(ctx contractapi.TransactionContextInterface, name string) (*TestMethod, error) {
+	method, err := readTestMethod(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if method == nil {
+		return nil, fmt.Errorf("test method %s does not exist", name)
+	}
+
+	return method, nil
+}
+
+// GetAllTestMethods 获取全部测试方法
+func (s *SmartContract) GetAllTestMethods(ctx contractapi.TransactionContextInterface) ([]*TestMethod, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(testMethodDocType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	methods := []*TestMethod{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var method TestMethod
+		if err := json.Unmarshal(queryResponse.Value, &method); err != nil {
+			return nil, err
+		}
+		methods = append(methods, &method)
+	}
+
+	return methods, nil
+}
+
+// environmentProblems 检查环境条件是否满足测试方法的要求。
+// 测量值加减不确定度后的区间须完全落在方法限值内
+func environmentProblems(parameter string, conditions *EnvironmentalConditions, method *TestMethod) []string {
+	var problems []string
+	for _, quantity := range environmentQuantities(conditions, method) {
+		limit, reading := quantity.limit, quantity.reading
+		switch {
+		case limit == nil:
+		case reading == nil:
+			problems = append(problems, fmt.Sprintf("%s: %s not recorded (method %s requires %s to %s %s)", parameter,
+				quantity.name, method.Name, formatCanonicalFloat(limit.Min), formatCanonicalFloat(limit.Max), limit.Unit))
+		case reading.Unit != limit.Unit:
+			problems = append(problems, fmt.Sprintf("%s: %s unit %s does not match method %s unit %s", parameter,
+				quantity.name, reading.Unit, method.Name, limit.Unit))
+		case reading.Value-reading.Uncertainty < limit.Min || reading.Value+reading.Uncertainty > limit.Max:
+			problems = append(problems, fmt.Sprintf("%s: %s %s±%s %s outside method %s limits %s to %s %s", parameter,
+				quantity.name, formatCanonicalFloat(reading.Value), formatCanonicalFloat(reading.Uncertainty), reading.Unit,
+				method.Name, formatCanonicalFloat(limit.Min), formatCanonicalFloat(limit.Max), limit.Unit))
+		}
+	}
+
+	return problems
+}
+
+// checkEnvironmentalConditions 确认每项测试数据的环境条件满足其测试方法的要求，
+// 测试数据未单独记录环境条件时使用证书的环境条件，使用未登记方法的测试数据不能签发
+func checkEnvironmentalConditions(ctx contractapi.TransactionContextInterface, cert *Certificate, testDataJSON []byte) error {
+	if testDataJSON == nil {
+		return nil
+	}
+
+	var testData []TestDataItem
+	if err := json.Unmarshal(testDataJSON, &testData); err != nil {
+		return fmt.Errorf("failed to unmarshal test data of certificate %s: %v", cert.ID, err)
+	}
+
+	var problems []string
+	for _, item := range testData {
+		if item.Method == "" {
+			continue
+		}
+		method, err := readTestMethod(ctx, item.Method)
+		if err != nil {
+			return err
+		}
+		if method == nil {
+			problems = append(problems, fmt.Sprintf("%s: test method %s is not registered", item.Parameter, item.Method))
+			continue
+		}
+		if method.MSPID != certOrgMSP {
+			problems = append(problems, fmt.Sprintf("%s: test method %s is registered by %s, not by the issuing organisation",
+				item.Parameter, method.Name, method.MSPID))
+			continue
+		}
+
+		conditions := item.Environment
+		if conditions == nil {
+			conditions = cert.Environment
+		}
+		problems = append(problems, environmentProblems(item.Parameter, conditions, method)...)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("certificate %s cannot be issued: environmental conditions not met: %s",
+			cert.ID, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
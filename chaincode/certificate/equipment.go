@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// equipmentDocType 测量设备文档类型
+const equipmentDocType = "equipment"
+
+// Equipment 测量设备，通过当前校准证书溯源到上级计量标准
+type Equipment struct {
+	DocType                  string              `json:"docType"` // 文档类型，固定为equipment
+	ID                       string              `json:"id"`
+	Model                    string              `json:"model"`                                             // 型号
+	SerialNumber             string              `json:"serialNumber"`                                      // 出厂编号
+	OwnerOrg                 string              `json:"ownerOrg"`                                          // 设备所属机构
+	CalibrationCertificateID string              `json:"calibrationCertificateId"`                          // 当前校准证书ID
+	IsReferenceStandard      bool                `json:"isReferenceStandard"`                               // 是否为溯源链终点的基准或最高计量标准
+	CalibrationHistory       []CalibrationRecord `json:"calibrationHistory,omitempty" metadata:",optional"` // 历次登记的校准证书
+	CreatedAt                string              `json:"createdAt"`
+	UpdatedAt                string              `json:"updatedAt"`
+}
+
+// equipmentKey 返回设备的复合键
+func equipmentKey(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(equipmentDocType, []string{id})
+	if err != nil {
+		return "", fmt.Errorf("failed to create equipment key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readEquipment 从账本读取设备
+func readEquipment(ctx contractapi.TransactionContextInterface, id string) (*Equipment, error) {
+	key, err := equipmentKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	equipmentJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read equipment %s: %v", id, err)
+	}
+	if equipmentJSON == nil {
+		return nil, fmt.Errorf("equipment %s does not exist", id)
+	}
+
+	var equipment Equipment
+	if err := json.Unmarshal(equipmentJSON, &equipment); err != nil {
+		return nil, err
+	}
+
+	return &equipment, nil
+}
+
+// putEquipment 将设备写入账本
+func putEquipment(ctx contractapi.TransactionContextInterface, equipment *Equipment) error {
+	key, err := equipmentKey(ctx, equipment.ID)
+	if err != nil {
+		return err
+	}
+
+	equipmentJSON, err := json.Marshal(equipment)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(key, equipmentJSON)
+}
+
+// validateEquipment 校验设备必填字段，校准证书须已存在
+func (s *SmartContract) validateEquipment(ctx contractapi.TransactionContextInterface, equipment *Equipment) error {
+	if equipment.Model == "" || equipment.SerialNumber == "" || equipment.OwnerOrg == "" {
+		return fmt.Errorf("equipment model, serial number and owner org are required")
+	}
+
+	if equipment.CalibrationCertificateID != "" {
+		exists, err := s.CertificateExists(ctx, equipment.CalibrationCertificateID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("calibration certificate %s does not exist", equipment.CalibrationCertificateID)
+		}
+	}
+
+	return nil
+}
+
+// CreateEquipment 登记测量设备
+func (s *SmartContract) CreateEquipment(ctx contractapi.TransactionContextInterface, id string, equipmentData string) error {
+	if _, err := requireCertOrg(ctx); err != nil {
+		return err
+	}
+
+	if _, err := readEquipment(ctx, id); err == nil {
+		return fmt.Errorf("equipment %s already exists", id)
+	}
+
+	var equipment Equipment
+	if err := json.Unmarshal([]byte(equipmentData), &equipment); err != nil {
+		return fmt.Errorf("failed to unmarshal equipment data: %v", err)
+	}
+	if err := s.validateEquipment(ctx, &equipment); err != nil {
+		return err
+	}
+
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	equipment.DocType = equipmentDocType
+	equipment.ID = id
+	equipment.CreatedAt = now
+	equipment.UpdatedAt = now
+	equipment.CalibrationHistory = nil
+	recordCalibration(&equipment, now)
+
+	return putEquipment(ctx, &equipment)
+}
+
+// UpdateEquipment 更新测量设备，设备重新校准后在此登记新的校准证书
+func (s *SmartContract) UpdateEquipment(ctx contractapi.TransactionContextInterface, id string, equipmentData string) error {
+	if _, err := requireCertOrg(ctx); err != nil {
+		return err
+	}
+
+	equipment, err := readEquipment(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var updated Equipment
+	if err := json.Unmarshal([]byte(equipmentData), &updated); err != nil {
+		return fmt.Errorf("failed to unmarshal equipment data: %v", err)
+	}
+	if err := s.validateEquipment(ctx, &updated); err != nil {
+		return err
+	}
+
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	updated.DocType = equipmentDocType
+	updated.ID = equipment.ID
+	updated.CreatedAt = equipment.CreatedAt
+	updated.UpdatedAt = now
+	updated.CalibrationHistory = equipment.CalibrationHistory
+	recordCalibration(&updated, now)
+
+	return putEquipment(ctx, &updated)
+}
+
+// ReadEquipment 读取测量设备
+func (s *SmartContract) ReadEquipment(ctx contractapi.TransactionContextInterface, id string) (*Equipment, error) {
+	return readEquipment(ctx, id)
+}
+
+// GetAllEquipment 获取全部测量设备
+func (s *SmartContract) GetAllEquipment(ctx contractapi.TransactionContextInterface) ([]*Equipment, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(equipmentDocType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	equipmentList := []*Equipment{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var equipment Equipment
+		if err := json.Unmarshal(queryResponse.Value, &equipment); err != nil {
+			return nil, err
+		}
+		equipmentList = append(equipmentList, &equipment)
+	}
+
+	return equipmentList, nil
+}
+
+// setCertificateEquipment 根据测试数据引用的设备ID设置证书的公开设备列表，引用的设备须已登记。
+// 测试数据为私有数据，公开的设备列表用于溯源链和影响分析。
+func setCertificateEquipment(ctx contractapi.TransactionContextInterface, cert *Certificate, testDataJSON []byte) error {
+	cert.EquipmentIDs = nil
+	if testDataJSON == nil {
+		return nil
+	}
+
+	var testData []TestDataItem
+	if err := json.Unmarshal(testDataJSON, &testData); err != nil {
+		return fmt.Errorf("failed to unmarshal test data of certificate %s: %v", cert.ID, err)
+	}
+
+	seen := map[string]bool{}
+	for _, item := range testData {
+		if item.EquipmentID == "" || seen[item.EquipmentID] {
+			continue
+		}
+		if _, err := readEquipment(ctx, item.EquipmentID); err != nil {
+			return err
+		}
+
+		seen[item.EquipmentID] = true
+		cert.EquipmentIDs = append(cert.EquipmentIDs, item.EquipmentID)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// 证书生命周期事件名
+const (
+	eventCertificateCreated    = "CertificateCreated"
+	eventCertificateUpdated    = "CertificateUpdated"
+	eventCertificateSubmitted  = "CertificateSubmitted"
+	eventCertificateReviewed   = "CertificateReviewed"
+	eventCertificateApproved   = "CertificateApproved"
+	eventCertificateIssued     = "CertificateIssued"
+	eventCertificateSuspended  = "CertificateSuspended"
+	eventCertificateReinstated = "CertificateReinstated"
+	eventCertificateRevoked    = "CertificateRevoked"
+	eventCertificatesExpired   = "CertificatesExpired"
+	eventCertificatesRecalled  = "CertificatesRecalled"
+)
+
+// CertificateEvent 证书生命周期事件载荷，供下游通知和链下同步使用
+type CertificateEvent struct {
+	ID            string `json:"id"`
+	CertificateNo string `json:"certificateNo"`
+	Status        string `json:"status"`
+	InspectionOrg string `json:"inspectionOrg"`
+	OwnerMSP      string `json:"ownerMsp"`
+	Timestamp     string `json:"timestamp"`
+}
+
+// CertificatesExpiredEvent 批量过期事件载荷
+type CertificatesExpiredEvent struct {
+	IDs       []string `json:"ids"`
+	Timestamp string   `json:"timestamp"`
+}
+
+// CertificatesRecalledEvent 影响分析批量召回事件载荷
+type CertificatesRecalledEvent struct {
+	SourceType string   `json:"sourceType"`
+	SourceID   string   `json:"sourceId"`
+	Action     string   `json:"action"`
+	IDs        []string `json:"ids"`
+	Timestamp  string   `json:"timestamp"`
+}
+
+// emitCertificateEvent 设置证书事件，每个交易只能设置一个事件，后设置的会覆盖先设置的
+func emitCertificateEvent(ctx contractapi.TransactionContextInterface, name string, cert *Certificate, timestamp string) error {
+	payload, err := json.Marshal(CertificateEvent{
+		ID:            cert.ID,
+		CertificateNo: cert.CertificateNo,
+		Status:        cert.Status,
+		InspectionOrg: cert.InspectionOrg,
+		OwnerMSP:      cert.OwnerMSP,
+		Timestamp:     timestamp,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %v", name, err)
+	}
+
+	return ctx.GetStub().SetEvent(name, payload)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// parseValidUntil 解析有效期并返回失效时刻。仅含日期时证书在当天（UTC）内仍然有效
+func parseValidUntil(validUntil string) (time.Time, error) {
+	if t, err := time.Parse(dateLayout, validUntil); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+
+	t, err := time.Parse(timestampLayout, validUntil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid validUntil %q: expected %s or RFC3339", validUntil, dateLayout)
+	}
+
+	return t.UTC(), nil
+}
+
+// normalizeValidUntil 校验有效期并将带时区的时间转换为UTC，
+// 保证过期扫描按字典序比较时与实际失效时刻一致，仅含日期的有效期保持不变
+func normalizeValidUntil(validUntil *string) error {
+	if _, err := time.Parse(dateLayout, *validUntil); err == nil {
+		return nil
+	}
+
+	t, err := parseValidUntil(*validUntil)
+	if err != nil {
+		return err
+	}
+	*validUntil = formatTimestamp(t)
+
+	return nil
+}
+
+// isExpired 判断证书在给定时刻是否已超过有效期
+func isExpired(cert *Certificate, at time.Time) bool {
+	expiresAt, err := parseValidUntil(cert.ValidUntil)
+	if err != nil {
+		return false
+	}
+
+	return !at.Before(expiresAt)
+}
+
+// applyEffectiveStatus 读取时将已超过有效期的已签发证书视为过期，不修改账本
+func applyEffectiveStatus(cert *Certificate, at time.Time) {
+	if cert.Status == statusIssued && isExpired(cert, at) {
+		cert.Status = statusExpired
+	}
+}
+
+// ExpireCertificates 将所有超过有效期的已签发证书持久化为过期状态，返回被处理的证书ID
+func (s *SmartContract) ExpireCertificates(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	identity, err := requireCertOrg(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	txTime, err := getTxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := formatTimestamp(txTime)
+
+	// 按字典序筛选候选证书，再按精确的失效时刻判断。
+	// 富查询结果在提交时不会重新校验，重复执行是安全的。
+	queryJSON, err := json.Marshal(map[string]interface{}{
+		"selector": map[string]interface{}{
+			"docType":    certificateDocType,
+			"status":     statusIssued,
+			"validUntil": map[string]interface{}{"$lt": now},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal query: %v", err)
+	}
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(string(queryJSON))
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	candidates, err := readCertificatesFromIterator(resultsIterator)
+	if err != nil {
+		return nil, err
+	}
+
+	expired := []string{}
+	for _, cert := range candidates {
+		if !isExpired(cert, txTime) {
+			continue
+		}
+
+		cert.Status = statusExpired
+		cert.UpdatedAt = now
+
+		traceRecord := newTraceRecord(identity, now, "EXPIRED", fmt.Sprintf("Certificate expired. Valid until: %s", cert.ValidUntil))
+		cert.TraceHistory = append(cert.TraceHistory, traceRecord)
+
+		if err := putCertificate(ctx, cert); err != nil {
+			return nil, err
+		}
+		expired = append(expired, cert.ID)
+	}
+
+	if len(expired) > 0 {
+		payload, err := json.Marshal(CertificatesExpiredEvent{IDs: expired, Timestamp: now})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s event: %v", eventCertificatesExpired, err)
+		}
+		if err := ctx.GetStub().SetEvent(eventCertificatesExpired, payload); err != nil {
+			return nil, err
+		}
+	}
+
+	return expired, nil
+}
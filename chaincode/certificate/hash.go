@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// VerificationResult 证书哈希校验结果
+type VerificationResult struct {
+	CertificateID       string `json:"certificateId"`
+	CertificateNo       string `json:"certificateNo"`
+	Hash                string `json:"hash"`                // 账本上记录的证书内容哈希
+	HashAlgorithm       string `json:"hashAlgorithm"`       // 内容哈希算法
+	Match               bool   `json:"match"`               // 提交的哈希是否与账本一致
+	Valid               bool   `json:"valid"`               // 哈希一致且证书处于有效签发状态
+	Status              string `json:"status"`              // 证书当前状态
+	InspectionOrg       string `json:"inspectionOrg"`       // 检验机构
+	AccreditationStatus string `json:"accreditationStatus"` // 认可状态
+	AccreditationNo     string `json:"accreditationNo"`     // 认可证书编号
+	IssuedBy            string `json:"issuedBy"`            // 签发人
+	IssuedDate          string `json:"issuedDate"`
+}
+
+// canonicalTestDataItem 测试数据的规范化表示，浮点数按最短十进制表示输出
+func canonicalTestDataItem(item TestDataItem) map[string]interface{} {
+	return map[string]interface{}{
+		"parameter":     item.Parameter,
+		"measuredValue": formatCanonicalFloat(item.MeasuredValue),
+		"unit":          item.Unit,
+		"uncertainty":   formatCanonicalFloat(item.Uncertainty),
+		"method":        item.Method,
+		"equipment":     item.Equipment,
+		"equipmentId":   item.EquipmentID,
+		"environment":   canonicalEnvironment(item.Environment),
+	}
+}
+
+// formatCanonicalFloat 浮点数固定格式：不使用指数表示，保留区分该值所需的最少位数
+func formatCanonicalFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// canonicalCertificateContent 证书内容的规范化序列化。
+// 只包含证书的业务内容，不含状态、时间和流程字段；对象键按字典序排列，
+// 不转义HTML字符，数值统一为字符串，便于任何一方独立复算。
+// 所有键始终输出，缺少的对象为null，空字符串保留，使同一内容只有一种序列化。
+func canonicalCertificateContent(cert *Certificate, testData []TestDataItem) ([]byte, error) {
+	items := make([]map[string]interface{}, 0, len(testData))
+	for _, item := range testData {
+		items = append(items, canonicalTestDataItem(item))
+	}
+
+	// 盐值随测试数据保存在私有数据集合中并打印在证书上，使内容哈希无法通过穷举测试数据复原
+	content := map[string]interface{}{
+		"certificateNo": cert.CertificateNo,
+		"customerId":    cert.CustomerID,
+		"testUnit":      cert.TestUnit,
+		"ownerMsp":      cert.OwnerMSP,
+		"item":          canonicalItem(cert.Item),
+		"testDate":      cert.TestDate,
+		"environment":   canonicalEnvironment(cert.Environment),
+		"testData":      items,
+		"inspectionOrg": cert.InspectionOrg,
+		"inspector":     cert.Inspector,
+		"validUntil":    cert.ValidUntil,
+		"salt":          cert.Salt,
+	}
+
+	// encoding/json对map按键排序输出
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(content); err != nil {
+		return nil, fmt.Errorf("failed to marshal canonical certificate content: %v", err)
+	}
+
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// computeCertificateHash 使用证书指定的哈希算法计算证书内容哈希
+func computeCertificateHash(cert *Certificate, testData []TestDataItem) (string, error) {
+	content, err := canonicalCertificateContent(cert, testData)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := digest(cert.HashAlgorithm, content)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash), nil
+}
+
+// setCertificateHash 根据证书内容、私有测试数据原文及其盐值计算并记录证书哈希及所用算法，
+// 没有测试数据时不计入盐值
+func setCertificateHash(cert *Certificate, salt string, testDataJSON []byte) error {
+	algorithm, err := normalizeHashAlgorithm(cert.HashAlgorithm)
+	if err != nil {
+		return err
+	}
+	cert.HashAlgorithm = algorithm
+
+	var testData []TestDataItem
+	cert.Salt = ""
+	if testDataJSON != nil {
+		if err := json.Unmarshal(testDataJSON, &testData); err != nil {
+			return fmt.Errorf("failed to unmarshal test data of certificate %s: %v", cert.ID, err)
+		}
+		cert.Salt = salt
+	}
+
+	hash, err := computeCertificateHash(cert, testData)
+	cert.Salt = ""
+	if err != nil {
+		return err
+	}
+
+	cert.Hash = hash
+	return nil
+}
+
+// ComputeCertificateHash 按证书记录的哈希算法计算完整证书JSON（含测试数据和盐值）的内容哈希，供持有证书的一方复算
+func (s *SmartContract) ComputeCertificateHash(ctx contractapi.TransactionContextInterface, certificateData string) (string, error) {
+	var cert Certificate
+	if err := json.Unmarshal([]byte(certificateData), &cert); err != nil {
+		return "", fmt.Errorf("failed to unmarshal certificate data: %v", err)
+	}
+
+	return computeCertificateHash(&cert, cert.TestData)
+}
+
+// GetCanonicalCertificateContent 返回完整证书JSON（含测试数据和盐值）的规范化内容，
+// 持有证书的一方可按证书记录的哈希算法在链下自行计算摘要
+func (s *SmartContract) GetCanonicalCertificateContent(ctx contractapi.TransactionContextInterface, certificateData string) (string, error) {
+	var cert Certificate
+	if err := json.Unmarshal([]byte(certificateData), &cert); err != nil {
+		return "", fmt.Errorf("failed to unmarshal certificate data: %v", err)
+	}
+
+	content, err := canonicalCertificateContent(&cert, cert.TestData)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+// VerifyCertificate 校验证书哈希是否与账本一致，并返回证书当前状态和签发方。
+// 校验结果不包含证书内容，因此对通道内所有成员开放。
+func (s *SmartContract) VerifyCertificate(ctx contractapi.TransactionContextInterface, id string, hash string) (*VerificationResult, error) {
+	cert, err := s.readCertificate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	applyEffectiveStatus(cert, now)
+
+	match := hash != "" && strings.EqualFold(hash, cert.Hash)
+
+	return &VerificationResult{
+		CertificateID:       cert.ID,
+		CertificateNo:       cert.CertificateNo,
+		Hash:                cert.Hash,
+		HashAlgorithm:       cert.HashAlgorithm,
+		Match:               match,
+		Valid:               match && cert.Status == statusIssued,
+		Status:              cert.Status,
+		InspectionOrg:       cert.InspectionOrg,
+		AccreditationStatus: cert.AccreditationStatus,
+		AccreditationNo:     cert.AccreditationNo,
+		IssuedBy:            cert.IssuedBy,
+		IssuedDate:          cert.IssuedDate,
+	}, nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import "testing"
+
+// 证书内容哈希的固定向量，期望值由openssl dgst -sha256/-sm3对规范化内容独立计算，
+// 规范化规则或哈希实现的任何变化都会使已签发证书无法复算
+func TestCertificateHashGoldenVectors(t *testing.T) {
+	tests := []struct {
+		name      string
+		cert      *Certificate
+		testData  []TestDataItem
+		canonical string
+		sha256    string
+		sm3       string
+	}{
+		{
+			name: "salted",
+			cert: &Certificate{
+				CertificateNo: "CERT-2025-001",
+				CustomerID:    "914403001922038216",
+				TestUnit:      "华为技术有限公司",
+				OwnerMSP:      "TestOrgMSP",
+				Item:          &CalibratedItem{Manufacturer: "Fluke", Model: "8846A", SerialNumber: "SN-8846A-0001"},
+				TestDate:      "2025-01-15",
+				Environment: &EnvironmentalConditions{
+					Temperature: &EnvironmentReading{Value: 20.1, Unit: "℃", Uncertainty: 0.2},
+				},
+				InspectionOrg: "NIM",
+				Inspector:     "INSP-001",
+				ValidUntil:    "2026-01-15",
+				Salt:          "000102030405060708090a0b0c0d0e0f",
+			},
+			testData: []TestDataItem{
+				{Parameter: "电压", MeasuredValue: 220.5, Unit: "V", Uncertainty: 0.1, Method: "直接测量法", Equipment: "数字万用表", EquipmentID: "EQ-001"},
+			},
+			canonical: `{"certificateNo":"CERT-2025-001","customerId":"914403001922038216","environment":{"humidity":null,"pressure":null,"temperature":{"uncertainty":"0.2","unit":"℃","value":"20.1"}},"inspectionOrg":"NIM","inspector":"INSP-001","item":{"assetTag":"","description":"","manufacturer":"Fluke","model":"8846A","serialNumber":"SN-8846A-0001"},"ownerMsp":"TestOrgMSP","salt":"000102030405060708090a0b0c0d0e0f","testData":[{"environment":null,"equipment":"数字万用表","equipmentId":"EQ-001","measuredValue":"220.5","method":"直接测量法","parameter":"电压","uncertainty":"0.1","unit":"V"}],"testDate":"2025-01-15","testUnit":"华为技术有限公司","validUntil":"2026-01-15"}`,
+			sha256:    "fd1570afe0d817fd98af13d8db6361fa7852a8beda6c28a730bd6b9f8f26cc89",
+			sm3:       "3173d898e8609242aff0725b177c866f493355c2963eb991113b534555ca4d48",
+		},
+		{
+			// 没有被校设备、环境条件和测试数据的证书，缺少的对象输出为null，空盐值和空数组保留
+			name: "minimal",
+			cert: &Certificate{
+				CertificateNo: "CERT-2025-002",
+				CustomerID:    "914403001922038216",
+				TestUnit:      "华为技术有限公司",
+				OwnerMSP:      "TestOrgMSP",
+				TestDate:      "2025-02-01",
+				InspectionOrg: "NIM",
+				Inspector:     "INSP-001",
+				ValidUntil:    "2026-02-01T00:00:00Z",
+			},
+			canonical: `{"certificateNo":"CERT-2025-002","customerId":"914403001922038216","environment":null,"inspectionOrg":"NIM","inspector":"INSP-001","item":null,"ownerMsp":"TestOrgMSP","salt":"","testData":[],"testDate":"2025-02-01","testUnit":"华为技术有限公司","validUntil":"2026-02-01T00:00:00Z"}`,
+			sha256:    "882f7b66cc19d759255c1a83730702e6c152b966dd98a5a774c94f462cffcf6e",
+			sm3:       "1e265d01e3e75470ca2e13d47b7b73012ca4fe170bbdd044cd4e8984200b04c5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := canonicalCertificateContent(tt.cert, tt.testData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tt.canonical {
+				t.Errorf("canonical content = %s, want %s", content, tt.canonical)
+			}
+
+			for algorithm, want := range map[string]string{hashAlgorithmSHA256: tt.sha256, hashAlgorithmSM3: tt.sm3} {
+				cert := *tt.cert
+				cert.HashAlgorithm = algorithm
+				got, err := computeCertificateHash(&cert, tt.testData)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != want {
+					t.Errorf("%s hash = %s, want %s", algorithm, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// 影响分析的源头类型
+const (
+	impactSourceCertificate = "certificate"
+	impactSourceEquipment   = "equipment"
+)
+
+// 批量召回方式：suspend暂停有效证书并标记其余证书，flag仅标记
+const (
+	recallActionSuspend = "suspend"
+	recallActionFlag    = "flag"
+)
+
+// ImpactNode 影响树中的证书节点
+type ImpactNode struct {
+	CertificateID string                 `json:"certificateId"`
+	CertificateNo string                 `json:"certificateNo"`
+	Status        string                 `json:"status"`
+	TestDate      string                 `json:"testDate"`
+	OwnerMSP      string                 `json:"ownerMsp"`
+	Equipment     []*ImpactEquipmentNode `json:"equipment,omitempty" metadata:",optional"` // 以本证书为校准证书的设备
+}
+
+// ImpactEquipmentNode 影响树中的设备节点
+type ImpactEquipmentNode struct {
+	EquipmentID  string        `json:"equipmentId"`
+	Model        string        `json:"model"`
+	SerialNumber string        `json:"serialNumber"`
+	OwnerOrg     string        `json:"ownerOrg"`
+	Certificates []*ImpactNode `json:"certificates,omitempty" metadata:",optional"` // 使用该设备出具的证书
+}
+
+// ImpactReport 影响分析结果，根节点为源证书或源设备
+type ImpactReport struct {
+	SourceType             string               `json:"sourceType"`
+	SourceID               string               `json:"sourceId"`
+	Certificate            *ImpactNode          `json:"certificate,omitempty" metadata:",optional"`
+	Equipment              *ImpactEquipmentNode `json:"equipment,omitempty" metadata:",optional"`
+	AffectedCertificateIDs []string             `json:"affectedCertificateIds"` // 直接或间接受影响的证书，不含源证书
+}
+
+// impactWalker 沿校准证书→设备→证书方向遍历下游，避免循环引用。每个证书只出现一次；
+// 设备按校准证书区分，同一设备在不同校准证书下各自只筛选该校准有效期内的证书
+type impactWalker struct {
+	ctx          contractapi.TransactionContextInterface
+	now          time.Time
+	certificates map[string]bool
+	equipment    map[impactEquipmentKey]bool
+	affected     []string
+}
+
+// impactEquipmentKey 已遍历的设备及其所依据的校准证书，从源设备出发时校准证书ID为空
+type impactEquipmentKey struct {
+	equipmentID   string
+	calibrationID string
+}
+
+// equipmentCalibratedBy 查询登记过指定校准证书的设备，包括已重新校准的设备
+func equipmentCalibratedBy(ctx contractapi.TransactionContextInterface, certificateID string) ([]*Equipment, error) {
+	queryJSON, err := json.Marshal(map[string]interface{}{
+		"selector": map[string]interface{}{
+			"docType": equipmentDocType,
+			"$or": []interface{}{
+				map[string]interface{}{"calibrationCertificateId": certificateID},
+				map[string]interface{}{"calibrationHistory": map[string]interface{}{
+					"$elemMatch": map[string]interface{}{"certificateId": certificateID},
+				}},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal query: %v", err)
+	}
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(string(queryJSON))
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var equipmentList []*Equipment
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var equipment Equipment
+		if err := json.Unmarshal(queryResponse.Value, &equipment); err != nil {
+			return nil, err
+		}
+		equipmentList = append(equipmentList, &equipment)
+	}
+
+	return equipmentList, nil
+}
+
+// certificatesUsingEquipment 查询测试数据引用了指定设备的证书
+func certificatesUsingEquipment(ctx contractapi.TransactionContextInterface, equipmentID string) ([]*Certificate, error) {
+	queryJSON, err := json.Marshal(map[string]interface{}{
+		"selector": map[string]interface{}{
+			"docType":      certificateDocType,
+			"equipmentIds": map[string]interface{}{"$elemMatch": map[string]interface{}{"$eq": equipmentID}},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal query: %v", err)
+	}
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(string(queryJSON))
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	return readCertificatesFromIterator(resultsIterator)
+}
+
+// calibrationCoversTest 判断测试是否在校准证书的签发日期和有效期之间进行，即测试是否依赖该校准结果。
+// 日期无法解析时按受影响处理
+func calibrationCoversTest(calibration *Certificate, testDate string) bool {
+	testStart, testEnd, err := parseTestDate(testDate)
+	if err != nil {
+		return true
+	}
+
+	if issuedAt, err := time.Parse(timestampLayout, calibration.IssuedDate); err == nil && !issuedAt.Before(testEnd) {
+		return false
+	}
+	if expiresAt, err := parseValidUntil(calibration.ValidUntil); err == nil && !testStart.Before(expiresAt) {
+		return false
+	}
+
+	return true
+}
+
+// certificateNode 构造证书节点，并继续查找以该证书为校准证书的设备
+func (w *impactWalker) certificateNode(cert *Certificate) (*ImpactNode, error) {
+	w.certificates[cert.ID] = true
+
+	node := &ImpactNode{
+		CertificateID: cert.ID,
+		CertificateNo: cert.CertificateNo,
+		TestDate:      cert.TestDate,
+		OwnerMSP:      cert.OwnerMSP,
+	}
+
+	equipmentList, err := equipmentCalibratedBy(w.ctx, cert.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, equipment := range equipmentList {
+		if w.equipment[impactEquipmentKey{equipment.ID, cert.ID}] {
+			continue
+		}
+
+		equipmentNode, err := w.equipmentNode(equipment, cert)
+		if err != nil {
+			return nil, err
+		}
+		node.Equipment = append(node.Equipment, equipmentNode)
+	}
+
+	applyEffectiveStatus(cert, w.now)
+	node.Status = cert.Status
+
+	return node, nil
+}
+
+// equipmentNode 构造设备节点，并继续查找使用该设备出具的证书。
+// calibration非空时只包含在该校准证书有效期内测试的证书
+func (w *impactWalker) equipmentNode(equipment *Equipment, calibration *Certificate) (*ImpactEquipmentNode, error) {
+	key := impactEquipmentKey{equipmentID: equipment.ID}
+	if calibration != nil {
+		key.calibrationID = calibration.ID
+	}
+	w.equipment[key] = true
+
+	node := &ImpactEquipmentNode{
+		EquipmentID:  equipment.ID,
+		Model:        equipment.Model,
+		SerialNumber: equipment.SerialNumber,
+		OwnerOrg:     equipment.OwnerOrg,
+	}
+
+	certificates, err := certificatesUsingEquipment(w.ctx, equipment.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, cert := range certificates {
+		if w.certificates[cert.ID] {
+			continue
+		}
+		if calibration != nil && !calibrationCoversTest(calibration, cert.TestDate) {
+			continue
+		}
+
+		w.affected = append(w.affected, cert.ID)
+		certNode, err := w.certificateNode(cert)
+		if err != nil {
+			return nil, err
+		}
+		node.Certificates = append(node.Certificates, certNode)
+	}
+
+	return node, nil
+}
+
+// analyzeImpact 从源证书或源设备出发构造影响树
+func (s *SmartContract) analyzeImpact(ctx contractapi.TransactionContextInterface, sourceType string, sourceID string) (*ImpactReport, error) {
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &impactWalker{
+		ctx:          ctx,
+		now:          now,
+		certificates: map[string]bool{},
+		equipment:    map[impactEquipmentKey]bool{},
+		affected:     []string{},
+	}
+	report := &ImpactReport{SourceType: sourceType, SourceID: sourceID}
+
+	switch sourceType {
+	case impactSourceCertificate:
+		cert, err := s.readCertificate(ctx, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		if report.Certificate, err = w.certificateNode(cert); err != nil {
+			return nil, err
+		}
+	case impactSourceEquipment:
+		equipment, err := readEquipment(ctx, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		if report.Equipment, err = w.equipmentNode(equipment, nil); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown impact source type %q, expected %s or %s", sourceType, impactSourceCertificate, impactSourceEquipment)
+	}
+
+	report.AffectedCertificateIDs = w.affected
+	return report, nil
+}
+
+// AnalyzeCertificateImpact 查找直接或间接依赖指定校准证书的全部证书，
+// 用于计量标准的校准证书被撤销后评估受影响范围
+func (s *SmartContract) AnalyzeCertificateImpact(ctx contractapi.TransactionContextInterface, id string) (*ImpactReport, error) {
+	if _, err := requireCertOrg(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.analyzeImpact(ctx, impactSourceCertificate, id)
+}
+
+// AnalyzeEquipmentImpact 查找直接或间接使用指定设备出具的全部证书
+func (s *SmartContract) AnalyzeEquipmentImpact(ctx contractapi.TransactionContextInterface, id string) (*ImpactReport, error) {
+	if _, err := requireCertOrg(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.analyzeImpact(ctx, impactSourceEquipment, id)
+}
+
+// dependsOnSource 沿证书→设备→校准证书方向向上追溯，判断证书是否直接或间接依赖源证书或源设备。
+// 与影响分析的向下遍历规则一致，但只按键读取，读集在提交时会重新校验。visited记录已追溯的证书以避免循环引用
+func (s *SmartContract) dependsOnSource(ctx contractapi.TransactionContextInterface, cert *Certificate, sourceType string, sourceID string, visited map[string]bool) (bool, error) {
+	visited[cert.ID] = true
+
+	for _, equipmentID := range cert.EquipmentIDs {
+		if sourceType == impactSourceEquipment && equipmentID == sourceID {
+			return true, nil
+		}
+
+		equipment, err := readEquipment(ctx, equipmentID)
+		if err != nil {
+			return false, err
+		}
+		for _, calibrationID := range calibrationCertificateIDs(equipment) {
+			if visited[calibrationID] {
+				continue
+			}
+			calibration, err := s.readCertificate(ctx, calibrationID)
+			if err != nil {
+				return false, err
+			}
+			if !calibrationCoversTest(calibration, cert.TestDate) {
+				continue
+			}
+			if sourceType == impactSourceCertificate && calibration.ID == sourceID {
+				return true, nil
+			}
+
+			depends, err := s.dependsOnSource(ctx, calibration, sourceType, sourceID, visited)
+			if err != nil || depends {
+				return depends, err
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// RecallAffectedCertificates 批量处理影响分析找到的证书：标记为存疑，suspend方式下同时暂停有效证书。
+// 富查询结果在提交时不会重新校验，因此由调用方先执行影响分析查询，再传入证书ID列表（JSON数组）；
+// 每个证书重新读取并确认依赖该源头。已撤销的证书不再处理，每个处理的证书记录一条溯源记录，返回处理的证书ID
+func (s *SmartContract) RecallAffectedCertificates(ctx contractapi.TransactionContextInterface, sourceType string, sourceID string, action string, reason string, certificateIDs string) ([]string, error) {
+	identity, err := requireRole(ctx, "recall certificates", roleApprover)
+	if err != nil {
+		return nil, err
+	}
+
+	if sourceType != impactSourceCertificate && sourceType != impactSourceEquipment {
+		return nil, fmt.Errorf("unknown impact source type %q, expected %s or %s", sourceType, impactSourceCertificate, impactSourceEquipment)
+	}
+	if action != recallActionSuspend && action != recallActionFlag {
+		return nil, fmt.Errorf("unknown recall action %q, expected %s or %s", action, recallActionSuspend, recallActionFlag)
+	}
+	if reason == "" {
+		return nil, fmt.Errorf("recall reason is required")
+	}
+
+	var ids []string
+	if err := json.Unmarshal([]byte(certificateIDs), &ids); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal certificate IDs: %v", err)
+	}
+
+	txTime, err := getTxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := formatTimestamp(txTime)
+	details := fmt.Sprintf("%s (impact of %s %s)", reason, sourceType, sourceID)
+
+	recalled := []string{}
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		cert, err := s.readCertificate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		depends, err := s.dependsOnSource(ctx, cert, sourceType, sourceID, map[string]bool{})
+		if err != nil {
+			return nil, err
+		}
+		if !depends {
+			return nil, fmt.Errorf("certificate %s is not affected by %s %s", id, sourceType, sourceID)
+		}
+		if cert.Status == statusRevoked {
+			continue
+		}
+
+		cert.FlaggedReason = details
+		cert.UpdatedAt = now
+
+		var traceRecord TraceRecord
+		if action == recallActionSuspend && cert.Status == statusIssued && !isExpired(cert, txTime) {
+			cert.Status = statusSuspended
+			cert.SuspensionReason = "equipment_issue"
+			traceRecord = newTraceRecord(identity, now, "SUSPENDED", fmt.Sprintf("Certificate suspended. Reason: equipment_issue. Details: %s", details))
+		} else {
+			traceRecord = newTraceRecord(identity, now, "FLAGGED", fmt.Sprintf("Certificate flagged as suspect. Details: %s", details))
+		}
+		cert.TraceHistory = append(cert.TraceHistory, traceRecord)
+
+		if err := putCertificate(ctx, cert); err != nil {
+			return nil, err
+		}
+		recalled = append(recalled, cert.ID)
+	}
+
+	if len(recalled) > 0 {
+		payload, err := json.Marshal(CertificatesRecalledEvent{
+			SourceType: sourceType,
+			SourceID:   sourceID,
+			Action:     action,
+			IDs:        recalled,
+			Timestamp:  now,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s event: %v", eventCertificatesRecalled, err)
+		}
+		if err := ctx.GetStub().SetEvent(eventCertificatesRecalled, payload); err != nil {
+			return nil, err
+		}
+	}
+
+	return recalled, nil
+}
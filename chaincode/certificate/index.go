@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// certNoIndex 证书编号到证书ID的复合键索引名
+const certNoIndex = "certNo~id"
+
+// hashIndex 证书内容哈希到证书ID的复合键索引名。
+// 内容变更后旧哈希的条目不删除，持有旧版本证书的一方仍能查到该证书的当前状态
+const hashIndex = "hash~id"
+
+// indexValue 索引条目只依赖键本身，值使用占位字节
+var indexValue = []byte{0x00}
+
+// findIDsByIndex 查询复合键索引中指定属性对应的全部证书ID
+func findIDsByIndex(ctx contractapi.TransactionContextInterface, index string, attribute string) ([]string, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(index, []string{attribute})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query index %s: %v", index, err)
+	}
+	defer resultsIterator.Close()
+
+	var ids []string
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		if len(attributes) < 2 {
+			return nil, fmt.Errorf("invalid %s index key %q", index, queryResponse.Key)
+		}
+		ids = append(ids, attributes[len(attributes)-1])
+	}
+
+	return ids, nil
+}
+
+// putIndex 写入复合键索引条目
+func putIndex(ctx contractapi.TransactionContextInterface, index string, attribute string, id string) error {
+	key, err := ctx.GetStub().CreateCompositeKey(index, []string{attribute, id})
+	if err != nil {
+		return fmt.Errorf("failed to create %s index key: %v", index, err)
+	}
+
+	return ctx.GetStub().PutState(key, indexValue)
+}
+
+// deleteIndex 删除复合键索引条目
+func deleteIndex(ctx contractapi.TransactionContextInterface, index string, attribute string, id string) error {
+	key, err := ctx.GetStub().CreateCompositeKey(index, []string{attribute, id})
+	if err != nil {
+		return fmt.Errorf("failed to create %s index key: %v", index, err)
+	}
+
+	return ctx.GetStub().DelState(key)
+}
+
+// checkCertificateNoAvailable 确保证书编号未被其他证书占用
+func checkCertificateNoAvailable(ctx contractapi.TransactionContextInterface, certificateNo string, id string) error {
+	if certificateNo == "" {
+		return fmt.Errorf("certificate number is required")
+	}
+
+	ids, err := findIDsByIndex(ctx, certNoIndex, certificateNo)
+	if err != nil {
+		return err
+	}
+
+	for _, existingID := range ids {
+		if existingID != id {
+			return fmt.Errorf("certificate number %s already exists (certificate %s)", certificateNo, existingID)
+		}
+	}
+
+	return nil
+}
+
+// ReadCertificateByNumber 按证书编号读取证书
+func (s *SmartContract) ReadCertificateByNumber(ctx contractapi.TransactionContextInterface, certificateNo string) (*Certificate, error) {
+	ids, err := findIDsByIndex(ctx, certNoIndex, certificateNo)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("certificate with number %s does not exist", certificateNo)
+	}
+
+	return s.ReadCertificate(ctx, ids[0])
+}
+
+// ReadCertificateByHash 按证书内容哈希读取证书。
+// 哈希可以是证书任一历史版本的内容哈希，返回的证书为当前版本，调用方比较hash字段即可判断内容是否已变更
+func (s *SmartContract) ReadCertificateByHash(ctx contractapi.TransactionContextInterface, hash string) (*Certificate, error) {
+	hash = strings.ToLower(hash)
+
+	identity, err := getClientIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := findIDsByIndex(ctx, hashIndex, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	// 多个证书先后使用过同一哈希时（如换发给其他送检单位的证书），跳过调用者无权读取的证书，
+	// 优先返回当前内容与哈希一致的证书。均不可读时与不存在一样处理，不泄露其他组织的证书
+	var first *Certificate
+	for _, id := range ids {
+		cert, err := s.readCertificate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !canReadCertificate(identity, cert) {
+			continue
+		}
+		applyEffectiveStatus(cert, now)
+
+		if cert.Hash == hash {
+			return cert, nil
+		}
+		if first == nil {
+			first = cert
+		}
+	}
+	if first == nil {
+		return nil, fmt.Errorf("certificate with hash %s does not exist", hash)
+	}
+
+	return first, nil
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// inspectorDocType 检验员文档类型
+const inspectorDocType = "inspector"
+
+// Inspector 登记的检验员及其资质，证书的Inspector字段为检验员ID
+type Inspector struct {
+	DocType              string   `json:"docType"` // 文档类型，固定为inspector
+	ID                   string   `json:"id"`
+	Name                 string   `json:"name"`
+	Identity             string   `json:"identity"`                                         // 检验员X.509证书主题，签发时须与签名者一致
+	MSPID                string   `json:"mspId"`                                            // 所属组织MSP，由登记的管理员确定
+	AuthorizedParameters []string `json:"authorizedParameters"`                             // 授权检测的参数
+	AuthorizedMethods    []string `json:"authorizedMethods,omitempty" metadata:",optional"` // 授权使用的测试方法，为空时不限制
+	QualifiedFrom        string   `json:"qualifiedFrom"`                                    // 资质有效期起
+	QualifiedUntil       string   `json:"qualifiedUntil"`                                   // 资质有效期至
+	CreatedAt            string   `json:"createdAt"`
+	UpdatedAt            string   `json:"updatedAt"`
+}
+
+// inspectorKey 返回检验员的复合键
+func inspectorKey(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(inspectorDocType, []string{id})
+	if err != nil {
+		return "", fmt.Errorf("failed to create inspector key: %v", err)
+	}
+
+	return key, nil
+}
+
+// readInspector 从账本读取检验员
+func readInspector(ctx contractapi.TransactionContextInterface, id string) (*Inspector, error) {
+	key, err := inspectorKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	inspectorJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inspector %s: %v", id, err)
+	}
+	if inspectorJSON == nil {
+		return nil, fmt.Errorf("inspector %s does not exist", id)
+	}
+
+	var inspector Inspector
+	if err := json.Unmarshal(inspectorJSON, &inspector); err != nil {
+		return nil, err
+	}
+
+	return &inspector, nil
+}
+
+// putInspector 将检验员写入账本
+func putInspector(ctx contractapi.TransactionContextInterface, inspector *Inspector) error {
+	key, err := inspectorKey(ctx, inspector.ID)
+	if err != nil {
+		return err
+	}
+
+	inspectorJSON, err := json.Marshal(inspector)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(key, inspectorJSON)
+}
+
+// parseQualification 解析资质有效期，返回生效和失效时刻
+func parseQualification(inspector *Inspector) (time.Time, time.Time, error) {
+	from, err := time.Parse(dateLayout, inspector.QualifiedFrom)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid qualifiedFrom %q: expected %s", inspector.QualifiedFrom, dateLayout)
+	}
+
+	until, err := parseValidUntil(inspector.QualifiedUntil)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid qualifiedUntil %q: %v", inspector.QualifiedUntil, err)
+	}
+	if !from.Before(until) {
+		return time.Time{}, time.Time{}, fmt.Errorf("qualifiedFrom %s is after qualifiedUntil %s", inspector.QualifiedFrom, inspector.QualifiedUntil)
+	}
+
+	return from, until, nil
+}
+
+// validateInspector 校验检验员必填字段和资质有效期
+func validateInspector(inspector *Inspector) error {
+	if inspector.Name == "" || inspector.Identity == "" {
+		return fmt.Errorf("inspector name and identity are required")
+	}
+	if len(inspector.AuthorizedParameters) == 0 {
+		return fmt.Errorf("inspector must be authorised for at least one parameter")
+	}
+
+	_, _, err := parseQualification(inspector)
+	return err
+}
+
+// CreateInspector 登记检验员，仅检验机构组织的管理员可调用
+func (s *SmartContract) CreateInspector(ctx contractapi.TransactionContextInterface, id string, inspectorData string) error {
+	identity, err := requireCertOrgAdmin(ctx, "register inspectors")
+	if err != nil {
+		return err
+	}
+
+	if _, err := readInspector(ctx, id); err == nil {
+		return fmt.Errorf("inspector %s already exists", id)
+	}
+
+	var inspector Inspector
+	if err := json.Unmarshal([]byte(inspectorData), &inspector); err != nil {
+		return fmt.Errorf("failed to unmarshal inspector data: %v", err)
+	}
+	if err := validateInspector(&inspector); err != nil {
+		return err
+	}
+
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	inspector.DocType = inspectorDocType
+	inspector.ID = id
+	inspector.MSPID = identity.MSPID
+	inspector.CreatedAt = now
+	inspector.UpdatedAt = now
+
+	return putInspector(ctx, &inspector)
+}
+
+// UpdateInspector 更新检验员的资质和授权范围，仅检验机构组织的管理员可调用
+func (s *SmartContract) UpdateInspector(ctx contractapi.TransactionContextInterface, id string, inspectorData string) error {
+	if _, err := requireCertOrgAdmin(ctx, "update inspectors"); err != nil {
+		return err
+	}
+
+	inspector, err := readInspector(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var updated Inspector
+	if err := json.Unmarshal([]byte(inspectorData), &updated); err != nil {
+		return fmt.Errorf("failed to unmarshal inspector data: %v", err)
+	}
+	if err := validateInspector(&updated); err != nil {
+		return err
+	}
+
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	updated.DocType = inspectorDocType
+	updated.ID = inspector.ID
+	updated.MSPID = inspector.MSPID
+	updated.CreatedAt = inspector.CreatedAt
+	updated.UpdatedAt = now
+
+	return putInspector(ctx, &updated)
+}
+
+// DeleteInspector 注销检验员，仅检验机构组织的管理员可调用。已签发的证书不受影响
+func (s *SmartContract) DeleteInspector(ctx contractapi.TransactionContextInterface, id string) error {
+	if _, err := requireCertOrgAdmin(ctx, "delete inspectors"); err != nil {
+		return err
+	}
+
+	if _, err := readInspector(ctx, id); err != nil {
+		return err
+	}
+
+	key, err := inspectorKey(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().DelState(key)
+}
+
+// ReadInspector 读取检验员
+func (s *SmartContract) ReadInspector(ctx contractapi.TransactionContextInterface, id string) (*Inspector, error) {
+	return readInspector(ctx, id)
+}
+
+// GetAllInspectors 获取全部检验员
+func (s *SmartContract) GetAllInspectors(ctx contractapi.TransactionContextInterface) ([]*Inspector, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(inspectorDocType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	inspectors := []*Inspector{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var inspector Inspector
+		if err := json.Unmarshal(queryResponse.Value, &inspector); err != nil {
+			return nil, err
+		}
+		inspectors = append(inspectors, &inspector)
+	}
+
+	return inspectors, nil
+}
+
+// containsString 判断列表中是否包含指定字符串
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkInspectorQualified 确认证书的检验员已由签发组织登记，测试日期在资质有效期内，
+// 且有权检测测试数据中的全部参数和方法。at非零时同时要求该时刻资质有效
+func checkInspectorQualified(ctx contractapi.TransactionContextInterface, cert *Certificate, testDataJSON []byte, at time.Time) (*Inspector, error) {
+	if cert.Inspector == "" {
+		return nil, fmt.Errorf("inspector is required")
+	}
+
+	inspector, err := readInspector(ctx, cert.Inspector)
+	if err != nil {
+		return nil, fmt.Errorf("inspector %s is not registered", cert.Inspector)
+	}
+	if inspector.MSPID != certOrgMSP {
+		return nil, fmt.Errorf("inspector %s is registered by %s, not by the issuing organisation", inspector.ID, inspector.MSPID)
+	}
+
+	from, until, err := parseQualification(inspector)
+	if err != nil {
+		return nil, err
+	}
+	testStart, _, err := parseTestDate(cert.TestDate)
+	if err != nil {
+		return nil, err
+	}
+	if testStart.Before(from) || !testStart.Before(until) {
+		return nil, fmt.Errorf("inspector %s was not qualified on test date %s (qualified %s to %s)",
+			inspector.ID, cert.TestDate, inspector.QualifiedFrom, inspector.QualifiedUntil)
+	}
+	if !at.IsZero() && (at.Before(from) || !at.Before(until)) {
+		return nil, fmt.Errorf("qualification of inspector %s is not valid on %s (qualified %s to %s)",
+			inspector.ID, formatTimestamp(at), inspector.QualifiedFrom, inspector.QualifiedUntil)
+	}
+
+	if testDataJSON == nil {
+		return inspector, nil
+	}
+
+	var testData []TestDataItem
+	if err := json.Unmarshal(testDataJSON, &testData); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal test data of certificate %s: %v", cert.ID, err)
+	}
+
+	var problems []string
+	for _, item := range testData {
+		if !containsString(inspector.AuthorizedParameters, item.Parameter) {
+			problems = append(problems, fmt.Sprintf("parameter %q", item.Parameter))
+		}
+		if len(inspector.AuthorizedMethods) > 0 && item.Method != "" && !containsString(inspector.AuthorizedMethods, item.Method) {
+			problems = append(problems, fmt.Sprintf("method %q", item.Method))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("inspector %s is not qualified for %s", inspector.ID, strings.Join(problems, ", "))
+	}
+
+	return inspector, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// itemSerialIndex 被校设备出厂编号到证书ID的复合键索引名，用于查询同一设备的历次校准证书
+const itemSerialIndex = "itemSerial~id"
+
+// CalibratedItem 证书对应的被校（被测）设备
+type CalibratedItem struct {
+	Manufacturer string `json:"manufacturer"` // 制造商
+	Model        string `json:"model"`        // 型号规格
+	SerialNumber string `json:"serialNumber"` // 出厂编号
+	AssetTag     string `json:"assetTag"`     // 客户资产编号
+	Description  string `json:"description"`  // 设备名称或描述
+}
+
+// validateItem 校验被校设备信息，未提供时不校验
+func validateItem(item *CalibratedItem) error {
+	if item == nil {
+		return nil
+	}
+
+	item.SerialNumber = strings.TrimSpace(item.SerialNumber)
+	if item.Manufacturer == "" || item.Model == "" || item.SerialNumber == "" {
+		return fmt.Errorf("item manufacturer, model and serial number are required")
+	}
+
+	return nil
+}
+
+// itemSerial 返回证书被校设备的出厂编号，未提供被校设备时为空
+func itemSerial(cert *Certificate) string {
+	if cert.Item == nil {
+		return ""
+	}
+
+	return cert.Item.SerialNumber
+}
+
+// canonicalItem 被校设备的规范化表示，未记录被校设备时为null
+func canonicalItem(item *CalibratedItem) interface{} {
+	if item == nil {
+		return nil
+	}
+
+	return map[string]string{
+		"manufacturer": item.Manufacturer,
+		"model":        item.Model,
+		"serialNumber": item.SerialNumber,
+		"assetTag":     item.AssetTag,
+		"description":  item.Description,
+	}
+}
+
+// QueryCertificatesBySerialNumber 按被校设备出厂编号查询该设备的历次证书，按测试日期排序
+func (s *SmartContract) QueryCertificatesBySerialNumber(ctx contractapi.TransactionContextInterface, serialNumber string) ([]*Certificate, error) {
+	ids, err := findIDsByIndex(ctx, itemSerialIndex, strings.TrimSpace(serialNumber))
+	if err != nil {
+		return nil, err
+	}
+
+	var certificates []*Certificate
+	for _, id := range ids {
+		cert, err := s.readCertificate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, cert)
+	}
+
+	certificates, err = filterReadableCertificates(ctx, certificates)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, cert := range certificates {
+		applyEffectiveStatus(cert, now)
+	}
+
+	sort.SliceStable(certificates, func(i, j int) bool {
+		return certificates[i].TestDate < certificates[j].TestDate
+	})
+
+	if certificates == nil {
+		certificates = []*Certificate{}
+	}
+
+	return certificates, nil
+}
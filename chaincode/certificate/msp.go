@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/emmansun/gmsm/smx509"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// mspRootObjectType 组织根证书登记的复合键类型
+const mspRootObjectType = "mspRoot"
+
+// adminOU 启用NodeOUs后组织管理员证书的OU
+const adminOU = "admin"
+
+// requireOrgAdmin 要求调用者为其所属组织的管理员，返回调用者身份
+func requireOrgAdmin(ctx contractapi.TransactionContextInterface, action string) (*clientIdentity, error) {
+	identity, err := getClientIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	x509Cert, err := ctx.GetClientIdentity().GetX509Certificate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client certificate: %v", err)
+	}
+
+	for _, ou := range x509Cert.Subject.OrganizationalUnit {
+		if ou == adminOU {
+			return identity, nil
+		}
+	}
+
+	return nil, permissionDenied("client %s is not an admin of %s and cannot %s", identity.Subject, identity.MSPID, action)
+}
+
+// requireCertOrgAdmin 要求调用者为检验机构组织的管理员。检验员、检验机构等登记以ID为键，
+// 若允许其他组织登记，可抢先占用检验机构将要使用的ID
+func requireCertOrgAdmin(ctx contractapi.TransactionContextInterface, action string) (*clientIdentity, error) {
+	identity, err := requireOrgAdmin(ctx, action)
+	if err != nil {
+		return nil, err
+	}
+	if identity.MSPID != certOrgMSP {
+		return nil, permissionDenied("client from %s cannot %s", identity.MSPID, action)
+	}
+
+	return identity, nil
+}
+
+// parseCertificatesPEM 解析PEM编码的证书列表，支持ECDSA和SM2证书
+func parseCertificatesPEM(pemData string) ([]*smx509.Certificate, error) {
+	var certs []*smx509.Certificate
+
+	rest := []byte(pemData)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := smx509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %v", err)
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no PEM encoded certificate found")
+	}
+
+	return certs, nil
+}
+
+// mspRootKey 返回组织根证书登记的键
+func mspRootKey(ctx contractapi.TransactionContextInterface, mspID string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(mspRootObjectType, []string{mspID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create MSP root key: %v", err)
+	}
+
+	return key, nil
+}
+
+// SetMSPRootCertificates 登记组织的根CA和中间CA证书（PEM），仅该组织的管理员可操作。
+// 链码无法读取通道的MSP配置，签名者身份校验以此登记为准。
+func (s *SmartContract) SetMSPRootCertificates(ctx contractapi.TransactionContextInterface, mspID string, certificatesPEM string) error {
+	identity, err := requireOrgAdmin(ctx, "register MSP root certificates")
+	if err != nil {
+		return err
+	}
+	if identity.MSPID != mspID {
+		return permissionDenied("admin of %s cannot register root certificates for %s", identity.MSPID, mspID)
+	}
+
+	certs, err := parseCertificatesPEM(certificatesPEM)
+	if err != nil {
+		return err
+	}
+	for _, cert := range certs {
+		if !cert.IsCA {
+			return fmt.Errorf("certificate %s is not a CA certificate", cert.Subject.String())
+		}
+	}
+
+	key, err := mspRootKey(ctx, mspID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(key, []byte(certificatesPEM))
+}
+
+// GetMSPRootCertificates 读取组织登记的CA证书（PEM）
+func (s *SmartContract) GetMSPRootCertificates(ctx contractapi.TransactionContextInterface, mspID string) (string, error) {
+	key, err := mspRootKey(ctx, mspID)
+	if err != nil {
+		return "", err
+	}
+
+	certificatesPEM, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read root certificates of %s: %v", mspID, err)
+	}
+	if certificatesPEM == nil {
+		return "", fmt.Errorf("no root certificates registered for %s", mspID)
+	}
+
+	return string(certificatesPEM), nil
+}
+
+// verifyMSPMember 校验证书由组织登记的CA签发且在指定时间有效
+func (s *SmartContract) verifyMSPMember(ctx contractapi.TransactionContextInterface, mspID string, cert *smx509.Certificate, at time.Time) error {
+	certificatesPEM, err := s.GetMSPRootCertificates(ctx, mspID)
+	if err != nil {
+		return err
+	}
+
+	caCerts, err := parseCertificatesPEM(certificatesPEM)
+	if err != nil {
+		return fmt.Errorf("invalid root certificates registered for %s: %v", mspID, err)
+	}
+
+	// 自签名证书作为根，其余作为中间证书
+	roots := smx509.NewCertPool()
+	intermediates := smx509.NewCertPool()
+	for _, caCert := range caCerts {
+		if caCert.CheckSignatureFrom(caCert) == nil {
+			roots.AddCert(caCert)
+		} else {
+			intermediates.AddCert(caCert)
+		}
+	}
+
+	_, err = cert.Verify(smx509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   at,
+		KeyUsages:     []smx509.ExtKeyUsage{smx509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("certificate %s is not a member of %s: %v", cert.Subject.String(), mspID, err)
+	}
+
+	return nil
+}
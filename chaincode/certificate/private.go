@@ -0,0 +1,193 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// testDataTransientKey 通过transient map传递测试数据时使用的键
+const testDataTransientKey = "testData"
+
+// saltTransientKey 通过transient map传递测试数据盐值时使用的键
+const saltTransientKey = "salt"
+
+// minSaltLength 盐值的最小字节数
+const minSaltLength = 16
+
+// privateTestData 私有数据集合中保存的测试数据记录。盐值由客户端随机生成并随测试数据传入，
+// 使公开账本上的哈希无法通过穷举测量值复原测试数据
+type privateTestData struct {
+	Salt     string          `json:"salt"`
+	TestData json.RawMessage `json:"testData"`
+}
+
+// testDataCollectionMSPs collections_config.json中定义了测试数据集合的送检单位组织MSP。
+// 新增送检单位组织时须在collections_config.json中添加集合testData<MSP>，在此登记该MSP，
+// 并以新的sequence重新批准和提交链码定义
+var testDataCollectionMSPs = map[string]bool{
+	"TestOrgMSP": true,
+}
+
+// testDataCollection 返回存放证书测试数据的私有数据集合名，
+// 该集合仅由检验机构和送检单位所属组织共享（见collections_config.json）
+func testDataCollection(ownerMSP string) string {
+	return "testData" + ownerMSP
+}
+
+// hashTestData 计算私有数据记录的SHA-256哈希，与对等节点记录的私有数据哈希一致
+func hashTestData(recordJSON []byte) string {
+	hash := sha256.Sum256(recordJSON)
+	return hex.EncodeToString(hash[:])
+}
+
+// getTransientSalt 从transient map读取盐值并以十六进制返回，未提供时返回空字符串
+func getTransientSalt(ctx contractapi.TransactionContextInterface) (string, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return "", fmt.Errorf("failed to get transient data: %v", err)
+	}
+
+	salt, ok := transientMap[saltTransientKey]
+	if !ok {
+		return "", nil
+	}
+	if len(salt) < minSaltLength {
+		return "", fmt.Errorf("salt must be at least %d random bytes", minSaltLength)
+	}
+
+	return hex.EncodeToString(salt), nil
+}
+
+// getTransientTestData 从transient map读取测试数据，未提供时返回nil
+func getTransientTestData(ctx contractapi.TransactionContextInterface) ([]byte, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transient data: %v", err)
+	}
+
+	testDataJSON, ok := transientMap[testDataTransientKey]
+	if !ok {
+		return nil, nil
+	}
+
+	// 校验格式并规范化后再写入私有数据
+	var testData []TestDataItem
+	if err := json.Unmarshal(testDataJSON, &testData); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transient test data: %v", err)
+	}
+
+	return json.Marshal(testData)
+}
+
+// putPrivateTestData 将测试数据及其盐值写入私有数据集合，并在公开证书上记录该记录的哈希
+func putPrivateTestData(ctx contractapi.TransactionContextInterface, cert *Certificate, salt string, testDataJSON []byte) error {
+	if cert.OwnerMSP == "" {
+		return fmt.Errorf("certificate %s has no owner MSP for its test data collection", cert.ID)
+	}
+	if salt == "" {
+		return fmt.Errorf("test data of certificate %s must be salted: pass a random salt in the transient map under key %q", cert.ID, saltTransientKey)
+	}
+
+	recordJSON, err := json.Marshal(privateTestData{Salt: salt, TestData: testDataJSON})
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().PutPrivateData(testDataCollection(cert.OwnerMSP), cert.ID, recordJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put private test data for certificate %s: %v", cert.ID, err)
+	}
+
+	cert.TestData = nil
+	cert.TestDataHash = hashTestData(recordJSON)
+	return nil
+}
+
+// readPrivateTestData 读取私有数据记录并校验其与证书记录的哈希一致，证书没有测试数据时返回nil
+func readPrivateTestData(ctx contractapi.TransactionContextInterface, cert *Certificate) (*privateTestData, error) {
+	if cert.TestDataHash == "" {
+		return nil, nil
+	}
+
+	recordJSON, err := ctx.GetStub().GetPrivateData(testDataCollection(cert.OwnerMSP), cert.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private test data for certificate %s: %v", cert.ID, err)
+	}
+	if recordJSON == nil {
+		return nil, fmt.Errorf("private test data for certificate %s is not available on this peer", cert.ID)
+	}
+
+	if hashTestData(recordJSON) != cert.TestDataHash {
+		return nil, fmt.Errorf("private test data for certificate %s does not match its hash", cert.ID)
+	}
+
+	var record privateTestData
+	if err := json.Unmarshal(recordJSON, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal private test data for certificate %s: %v", cert.ID, err)
+	}
+
+	return &record, nil
+}
+
+// getPrivateTestDataJSON 读取私有测试数据数组原文并校验其与证书记录的哈希一致
+func getPrivateTestDataJSON(ctx contractapi.TransactionContextInterface, cert *Certificate) ([]byte, error) {
+	record, err := readPrivateTestData(ctx, cert)
+	if err != nil || record == nil {
+		return nil, err
+	}
+
+	return record.TestData, nil
+}
+
+// getPrivateTestData 读取并解析证书的私有测试数据
+func getPrivateTestData(ctx contractapi.TransactionContextInterface, cert *Certificate) ([]TestDataItem, error) {
+	testDataJSON, err := getPrivateTestDataJSON(ctx, cert)
+	if err != nil || testDataJSON == nil {
+		return nil, err
+	}
+
+	var testData []TestDataItem
+	if err := json.Unmarshal(testDataJSON, &testData); err != nil {
+		return nil, err
+	}
+
+	return testData, nil
+}
+
+// ReadCertificateSalt 读取证书测试数据的盐值，打印在证书上供持有证书的一方复算内容哈希，
+// 仅检验机构和证书所属送检单位可读，证书没有测试数据时返回空字符串
+func (s *SmartContract) ReadCertificateSalt(ctx contractapi.TransactionContextInterface, id string) (string, error) {
+	cert, err := s.ReadCertificate(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	record, err := readPrivateTestData(ctx, cert)
+	if err != nil || record == nil {
+		return "", err
+	}
+
+	return record.Salt, nil
+}
+
+// ReadCertificateTestData 读取证书的私有测试数据，仅检验机构和证书所属送检单位可读
+func (s *SmartContract) ReadCertificateTestData(ctx contractapi.TransactionContextInterface, id string) ([]TestDataItem, error) {
+	cert, err := s.ReadCertificate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	testData, err := getPrivateTestData(ctx, cert)
+	if err != nil {
+		return nil, err
+	}
+	if testData == nil {
+		testData = []TestDataItem{}
+	}
+
+	return testData, nil
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// certificateDocType 证书文档类型，用于区分状态数据库中的不同资产
+const certificateDocType = "certificate"
+
+// dateLayout 日期字段（如testDate、validUntil）使用的格式
+const dateLayout = "2006-01-02"
+
+// sortableFields 允许排序的字段，每个字段都有对应的CouchDB索引
+var sortableFields = map[string]bool{
+	"testDate":      true,
+	"issuedDate":    true,
+	"validUntil":    true,
+	"certificateNo": true,
+}
+
+// CertificateFilter 证书查询条件，所有非空条件同时生效
+type CertificateFilter struct {
+	CustomerID     string `json:"customerId,omitempty"`
+	TestUnit       string `json:"testUnit,omitempty"` // 同时匹配名称或别名与之相同的客户的证书
+	Status         string `json:"status,omitempty"`
+	InspectionOrg  string `json:"inspectionOrg,omitempty"`
+	Inspector      string `json:"inspector,omitempty"`
+	TestDateFrom   string `json:"testDateFrom,omitempty"`
+	TestDateTo     string `json:"testDateTo,omitempty"`
+	IssuedDateFrom string `json:"issuedDateFrom,omitempty"`
+	IssuedDateTo   string `json:"issuedDateTo,omitempty"`
+	ValidUntilFrom string `json:"validUntilFrom,omitempty"`
+	ValidUntilTo   string `json:"validUntilTo,omitempty"`
+	SortBy         string `json:"sortBy,omitempty"`    // 排序字段
+	SortOrder      string `json:"sortOrder,omitempty"` // asc（默认）或desc
+}
+
+// QueryCertificates 按组合条件查询证书，filterData为CertificateFilter的JSON
+func (s *SmartContract) QueryCertificates(ctx contractapi.TransactionContextInterface, filterData string) ([]*Certificate, error) {
+	filter, err := parseCertificateFilter(filterData)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.queryCertificatesByFilter(ctx, filter)
+}
+
+// QueryCertificatesWithPagination 按组合条件分页查询证书
+func (s *SmartContract) QueryCertificatesWithPagination(ctx contractapi.TransactionContextInterface, filterData string, pageSize int32, bookmark string) (*PaginatedQueryResult, error) {
+	filter, err := parseCertificateFilter(filterData)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.queryCertificatesByFilterWithPagination(ctx, filter, pageSize, bookmark)
+}
+
+// queryCertificatesByFilter 按查询条件执行富查询
+func (s *SmartContract) queryCertificatesByFilter(ctx contractapi.TransactionContextInterface, filter *CertificateFilter) ([]*Certificate, error) {
+	queryString, err := buildCertificateQuery(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getQueryResultForQueryString(ctx, queryString)
+}
+
+// queryCertificatesByFilterWithPagination 按查询条件执行分页富查询
+func (s *SmartContract) queryCertificatesByFilterWithPagination(ctx contractapi.TransactionContextInterface, filter *CertificateFilter, pageSize int32, bookmark string) (*PaginatedQueryResult, error) {
+	queryString, err := buildCertificateQuery(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getQueryResultForQueryStringWithPagination(ctx, queryString, pageSize, bookmark)
+}
+
+// parseCertificateFilter 解析查询条件，空字符串表示不设条件
+func parseCertificateFilter(filterData string) (*CertificateFilter, error) {
+	var filter CertificateFilter
+	if filterData == "" {
+		return &filter, nil
+	}
+
+	if err := json.Unmarshal([]byte(filterData), &filter); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal certificate filter: %v", err)
+	}
+
+	return &filter, nil
+}
+
+// buildCertificateQuery 根据查询条件构造CouchDB查询语句，所有值均经过JSON编码
+func buildCertificateQuery(ctx contractapi.TransactionContextInterface, filter *CertificateFilter) (string, error) {
+	selector := map[string]interface{}{
+		"docType": certificateDocType,
+	}
+
+	// 非检验机构只能查询发给自己的证书
+	identity, err := getClientIdentity(ctx)
+	if err != nil {
+		return "", err
+	}
+	if identity.MSPID != certOrgMSP {
+		selector["ownerMsp"] = identity.MSPID
+	}
+
+	addEqualCondition(selector, "customerId", filter.CustomerID)
+	if err := addTestUnitCondition(ctx, selector, filter.TestUnit); err != nil {
+		return "", err
+	}
+	if err := addStatusCondition(ctx, selector, filter.Status); err != nil {
+		return "", err
+	}
+	addEqualCondition(selector, "inspectionOrg", filter.InspectionOrg)
+	addEqualCondition(selector, "inspector", filter.Inspector)
+
+	if err := addRangeCondition(selector, "testDate", filter.TestDateFrom, filter.TestDateTo); err != nil {
+		return "", err
+	}
+	if err := addRangeCondition(selector, "issuedDate", filter.IssuedDateFrom, filter.IssuedDateTo); err != nil {
+		return "", err
+	}
+	if err := addRangeCondition(selector, "validUntil", filter.ValidUntilFrom, filter.ValidUntilTo); err != nil {
+		return "", err
+	}
+
+	query := map[string]interface{}{"selector": selector}
+	if filter.SortBy != "" {
+		sort, err := buildSort(filter.SortBy, filter.SortOrder)
+		if err != nil {
+			return "", err
+		}
+		query["sort"] = sort
+	}
+
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal query: %v", err)
+	}
+
+	return string(queryJSON), nil
+}
+
+// buildSort 构造排序条件。索引为[docType, 字段]，排序需包含索引的全部字段且方向一致
+func buildSort(sortBy string, sortOrder string) ([]map[string]string, error) {
+	if !sortableFields[sortBy] {
+		return nil, fmt.Errorf("unsupported sort field %q", sortBy)
+	}
+
+	if sortOrder == "" {
+		sortOrder = "asc"
+	}
+	if sortOrder != "asc" && sortOrder != "desc" {
+		return nil, fmt.Errorf("unsupported sort order %q", sortOrder)
+	}
+
+	return []map[string]string{
+		{"docType": sortOrder},
+		{sortBy: sortOrder},
+	}, nil
+}
+
+// addEqualCondition 添加等值条件，空值忽略
+func addEqualCondition(selector map[string]interface{}, field string, value string) {
+	if value != "" {
+		selector[field] = value
+	}
+}
+
+// addAndCondition 以$and追加条件，避免与其他条件的$or等操作符冲突
+func addAndCondition(selector map[string]interface{}, condition map[string]interface{}) {
+	conditions, _ := selector["$and"].([]interface{})
+	selector["$and"] = append(conditions, condition)
+}
+
+// addStatusCondition 添加状态条件，按有效状态匹配，与查询结果显示的状态一致：
+// issued只匹配仍在有效期内的已签发证书，expired同时匹配已过有效期但尚未被ExpireCertificates处理的已签发证书。
+// 仅含日期的有效期在当天内有效，因此与当天日期相等的值视为未过期
+func addStatusCondition(ctx contractapi.TransactionContextInterface, selector map[string]interface{}, status string) error {
+	if status != statusIssued && status != statusExpired {
+		addEqualCondition(selector, "status", status)
+		return nil
+	}
+
+	txTime, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	now := formatTimestamp(txTime)
+	today := txTime.Format(dateLayout)
+
+	if status == statusIssued {
+		addAndCondition(selector, map[string]interface{}{
+			"status": statusIssued,
+			"$or": []interface{}{
+				map[string]interface{}{"validUntil": map[string]interface{}{"$gt": now}},
+				map[string]interface{}{"validUntil": today},
+			},
+		})
+		return nil
+	}
+
+	addAndCondition(selector, map[string]interface{}{
+		"$or": []interface{}{
+			map[string]interface{}{"status": statusExpired},
+			map[string]interface{}{
+				"status":     statusIssued,
+				"validUntil": map[string]interface{}{"$lte": now, "$ne": today},
+			},
+		},
+	})
+	return nil
+}
+
+// addTestUnitCondition 添加送检单位条件。名称或别名与之相同的客户的证书一并匹配，
+// 以覆盖同一单位的不同写法
+func addTestUnitCondition(ctx contractapi.TransactionContextInterface, selector map[string]interface{}, testUnit string) error {
+	if testUnit == "" {
+		return nil
+	}
+
+	customerIDs, err := findIDsByIndex(ctx, customerNameIndex, strings.TrimSpace(testUnit))
+	if err != nil {
+		return err
+	}
+	if len(customerIDs) == 0 {
+		selector["testUnit"] = testUnit
+		return nil
+	}
+
+	selector["$or"] = []interface{}{
+		map[string]interface{}{"testUnit": testUnit},
+		map[string]interface{}{"customerId": map[string]interface{}{"$in": customerIDs}},
+	}
+
+	return nil
+}
+
+// addRangeCondition 添加日期范围条件，from和to均可为空
+func addRangeCondition(selector map[string]interface{}, field string, from string, to string) error {
+	condition := map[string]interface{}{}
+
+	if from != "" {
+		bound, err := normalizeDateBound(from, false)
+		if err != nil {
+			return fmt.Errorf("invalid %s lower bound: %v", field, err)
+		}
+		condition["$gte"] = bound
+	}
+	if to != "" {
+		bound, err := normalizeDateBound(to, true)
+		if err != nil {
+			return fmt.Errorf("invalid %s upper bound: %v", field, err)
+		}
+		condition["$lte"] = bound
+	}
+
+	if len(condition) > 0 {
+		selector[field] = condition
+	}
+
+	return nil
+}
+
+// normalizeDateBound 校验日期边界并统一为可按字典序比较的字符串。
+// 仅含日期的上界扩展到当天结束，使其同时适用于日期和时间戳字段。
+func normalizeDateBound(value string, upper bool) (string, error) {
+	if _, err := time.Parse(dateLayout, value); err == nil {
+		if upper {
+			return value + "T23:59:59Z", nil
+		}
+		return value, nil
+	}
+
+	t, err := time.Parse(timestampLayout, value)
+	if err != nil {
+		return "", fmt.Errorf("%q is neither %s nor RFC3339", value, dateLayout)
+	}
+
+	return formatTimestamp(t), nil
+}
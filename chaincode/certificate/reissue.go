@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ReissueCertificate 以原证书内容创建换发草稿，新证书签发后原证书状态变为superseded
+func (s *SmartContract) ReissueCertificate(ctx contractapi.TransactionContextInterface, id string, newID string, newCertificateNo string) error {
+	identity, err := requireRole(ctx, "reissue certificates", roleInspector)
+	if err != nil {
+		return err
+	}
+
+	original, err := s.readCertificate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if original.Status != statusIssued && original.Status != statusSuspended {
+		return fmt.Errorf("certificate %s cannot be reissued in %s status", id, original.Status)
+	}
+	if original.SupersededBy != "" {
+		return fmt.Errorf("certificate %s is already being reissued as %s", id, original.SupersededBy)
+	}
+
+	exists, err := s.CertificateExists(ctx, newID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("certificate %s already exists", newID)
+	}
+
+	if err := checkCertificateNoAvailable(ctx, newCertificateNo, newID); err != nil {
+		return err
+	}
+
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	reissued := &Certificate{
+		DocType:       certificateDocType,
+		ID:            newID,
+		CertificateNo: newCertificateNo,
+		CustomerID:    original.CustomerID,
+		TestUnit:      original.TestUnit,
+		OwnerMSP:      original.OwnerMSP,
+		Item:          original.Item,
+		TestDate:      original.TestDate,
+		Environment:   original.Environment,
+		EquipmentIDs:  original.EquipmentIDs,
+		InspectionOrg: original.InspectionOrg,
+		Inspector:     original.Inspector,
+		Status:        statusDraft,
+		ValidUntil:    original.ValidUntil,
+		HashAlgorithm: original.HashAlgorithm,
+		CreatedBy:     identity.Subject,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Supersedes:    original.ID,
+	}
+	reissued.TraceHistory = append(reissued.TraceHistory,
+		newTraceRecord(identity, now, "CREATED", fmt.Sprintf("Certificate created as reissue of %s", original.ID)))
+
+	// 复制原证书的私有测试数据，transient map中提供盐值时使用新盐值
+	record, err := readPrivateTestData(ctx, original)
+	if err != nil {
+		return err
+	}
+	salt, err := getTransientSalt(ctx)
+	if err != nil {
+		return err
+	}
+	var testDataJSON []byte
+	if record != nil {
+		testDataJSON = record.TestData
+		if salt == "" {
+			salt = record.Salt
+		}
+	}
+	if err := setCertificateHash(reissued, salt, testDataJSON); err != nil {
+		return err
+	}
+	if testDataJSON != nil {
+		if err := putPrivateTestData(ctx, reissued, salt, testDataJSON); err != nil {
+			return err
+		}
+	}
+
+	original.SupersededBy = newID
+	original.UpdatedAt = now
+	original.TraceHistory = append(original.TraceHistory,
+		newTraceRecord(identity, now, "REISSUE_STARTED", fmt.Sprintf("Reissue started as certificate %s", newID)))
+
+	if err := putCertificate(ctx, reissued); err != nil {
+		return err
+	}
+	if err := putCertificate(ctx, original); err != nil {
+		return err
+	}
+	if err := putIndex(ctx, certNoIndex, reissued.CertificateNo, newID); err != nil {
+		return err
+	}
+	if serial := itemSerial(reissued); serial != "" {
+		if err := putIndex(ctx, itemSerialIndex, serial, newID); err != nil {
+			return err
+		}
+	}
+	if err := putIndex(ctx, hashIndex, reissued.Hash, newID); err != nil {
+		return err
+	}
+
+	return emitCertificateEvent(ctx, eventCertificateCreated, reissued, now)
+}
+
+// supersedeOriginal 换发证书签发时将原证书置为superseded
+func (s *SmartContract) supersedeOriginal(ctx contractapi.TransactionContextInterface, identity *clientIdentity, reissued *Certificate, now string) error {
+	original, err := s.readCertificate(ctx, reissued.Supersedes)
+	if err != nil {
+		return err
+	}
+
+	// 已撤销的原证书保持撤销状态，仅记录替代关联
+	if original.Status != statusRevoked {
+		original.Status = statusSuperseded
+		original.SuspensionReason = ""
+	}
+	original.SupersededBy = reissued.ID
+	original.UpdatedAt = now
+	original.TraceHistory = append(original.TraceHistory,
+		newTraceRecord(identity, now, "SUPERSEDED", fmt.Sprintf("Certificate superseded by %s", reissued.ID)))
+
+	return putCertificate(ctx, original)
+}
+
+// releaseOriginal 未签发的换发证书被撤销时解除原证书的替代关联
+func (s *SmartContract) releaseOriginal(ctx contractapi.TransactionContextInterface, identity *clientIdentity, reissued *Certificate, now string) error {
+	original, err := s.readCertificate(ctx, reissued.Supersedes)
+	if err != nil {
+		return err
+	}
+	if original.SupersededBy != reissued.ID {
+		return nil
+	}
+
+	original.SupersededBy = ""
+	original.UpdatedAt = now
+	original.TraceHistory = append(original.TraceHistory,
+		newTraceRecord(identity, now, "REISSUE_CANCELLED", fmt.Sprintf("Reissue as certificate %s was revoked before issuance", reissued.ID)))
+
+	return putCertificate(ctx, original)
+}
+
+// GetCertificateVersionChain 获取证书的完整版本链，按从最早到最新的顺序返回
+func (s *SmartContract) GetCertificateVersionChain(ctx contractapi.TransactionContextInterface, id string) ([]*Certificate, error) {
+	cert, err := s.ReadCertificate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 向前追溯到最初的证书
+	visited := map[string]bool{cert.ID: true}
+	for cert.Supersedes != "" {
+		if visited[cert.Supersedes] {
+			return nil, fmt.Errorf("certificate version chain of %s contains a cycle", id)
+		}
+		visited[cert.Supersedes] = true
+
+		cert, err = s.ReadCertificate(ctx, cert.Supersedes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 从最初的证书向后收集所有版本
+	chain := []*Certificate{cert}
+	seen := map[string]bool{cert.ID: true}
+	for cert.SupersededBy != "" {
+		if seen[cert.SupersededBy] {
+			return nil, fmt.Errorf("certificate version chain of %s contains a cycle", id)
+		}
+		seen[cert.SupersededBy] = true
+
+		cert, err = s.ReadCertificate(ctx, cert.SupersededBy)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, cert)
+	}
+
+	return chain, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// InspectorSignature 检验员对证书内容哈希的数字签名
+type InspectorSignature struct {
+	Algorithm         string `json:"algorithm"`         // 签名算法：ECDSA或SM2
+	Signature         string `json:"signature"`         // Base64编码的ASN.1 DER格式签名
+	SignerCertificate string `json:"signerCertificate"` // 签名者X.509证书（PEM）
+	Signer            string `json:"signer"`            // 签名者证书主题
+	SignerMSP         string `json:"signerMsp"`         // 签名者所属组织MSP
+}
+
+// verifyInspectorSignature 校验签名者属于指定组织，且签名是对证书内容哈希的有效签名，签名算法由签名者公钥确定
+func (s *SmartContract) verifyInspectorSignature(ctx contractapi.TransactionContextInterface, cert *Certificate, mspID string, signature string, signerCertificate string, at time.Time) (*InspectorSignature, error) {
+	if cert.Hash == "" {
+		return nil, fmt.Errorf("certificate %s has no content hash to sign", cert.ID)
+	}
+	contentHash, err := hex.DecodeString(cert.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content hash of certificate %s: %v", cert.ID, err)
+	}
+
+	signerCerts, err := parseCertificatesPEM(signerCertificate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signer certificate: %v", err)
+	}
+	signerCert := signerCerts[0]
+
+	if err := s.verifyMSPMember(ctx, mspID, signerCert, at); err != nil {
+		return nil, err
+	}
+
+	algorithm, publicKey, err := signatureAlgorithmOf(signerCert.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	signatureBytes, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return nil, fmt.Errorf("signature must be base64 encoded: %v", err)
+	}
+	if !signatureAlgorithms[algorithm](publicKey, contentHash, signatureBytes) {
+		return nil, fmt.Errorf("%s signature does not match the content hash of certificate %s", algorithm, cert.ID)
+	}
+
+	return &InspectorSignature{
+		Algorithm:         algorithm,
+		Signature:         signature,
+		SignerCertificate: signerCertificate,
+		Signer:            signerCert.Subject.String(),
+		SignerMSP:         mspID,
+	}, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// suspensionReasonCodes 允许的暂停原因代码
+var suspensionReasonCodes = map[string]bool{
+	"complaint":       true, // 客户投诉
+	"investigation":   true, // 内部调查
+	"equipment_issue": true, // 测试设备异常
+	"data_review":     true, // 测试数据复核
+	"other":           true, // 其他原因
+}
+
+// SuspendCertificate 暂停已签发证书，暂停期间证书不被视为有效
+func (s *SmartContract) SuspendCertificate(ctx contractapi.TransactionContextInterface, id string, reasonCode string, details string) error {
+	identity, err := requireRole(ctx, "suspend certificates", roleApprover)
+	if err != nil {
+		return err
+	}
+
+	if !suspensionReasonCodes[reasonCode] {
+		return fmt.Errorf("unknown suspension reason code %q", reasonCode)
+	}
+
+	cert, err := s.readCertificate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	txTime, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	applyEffectiveStatus(cert, txTime)
+	if cert.Status != statusIssued {
+		return fmt.Errorf("certificate %s cannot be suspended in %s status", id, cert.Status)
+	}
+
+	now := formatTimestamp(txTime)
+	cert.Status = statusSuspended
+	cert.SuspensionReason = reasonCode
+	cert.UpdatedAt = now
+
+	traceRecord := newTraceRecord(identity, now, "SUSPENDED", fmt.Sprintf("Certificate suspended. Reason: %s. Details: %s", reasonCode, details))
+	cert.TraceHistory = append(cert.TraceHistory, traceRecord)
+
+	if err := putCertificate(ctx, cert); err != nil {
+		return err
+	}
+
+	return emitCertificateEvent(ctx, eventCertificateSuspended, cert, now)
+}
+
+// ReinstateCertificate 恢复被暂停的证书
+func (s *SmartContract) ReinstateCertificate(ctx contractapi.TransactionContextInterface, id string, details string) error {
+	identity, err := requireRole(ctx, "reinstate certificates", roleApprover)
+	if err != nil {
+		return err
+	}
+
+	cert, err := s.readCertificate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if cert.Status != statusSuspended {
+		return fmt.Errorf("certificate %s is not in suspended status", id)
+	}
+
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	previousReason := cert.SuspensionReason
+	cert.Status = statusIssued
+	cert.SuspensionReason = ""
+	cert.FlaggedReason = ""
+	cert.UpdatedAt = now
+
+	traceRecord := newTraceRecord(identity, now, "REINSTATED", fmt.Sprintf("Certificate reinstated after suspension (%s). Details: %s", previousReason, details))
+	cert.TraceHistory = append(cert.TraceHistory, traceRecord)
+
+	if err := putCertificate(ctx, cert); err != nil {
+		return err
+	}
+
+	return emitCertificateEvent(ctx, eventCertificateReinstated, cert, now)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// TraceabilityNode 溯源链中的证书节点
+type TraceabilityNode struct {
+	CertificateID string                `json:"certificateId"`
+	CertificateNo string                `json:"certificateNo"`
+	Status        string                `json:"status"`
+	ValidUntil    string                `json:"validUntil"`
+	Traceable     bool                  `json:"traceable"` // 所有设备均可溯源到基准
+	Equipment     []*EquipmentTraceNode `json:"equipment,omitempty" metadata:",optional"`
+}
+
+// EquipmentTraceNode 溯源链中的设备节点
+type EquipmentTraceNode struct {
+	EquipmentID         string            `json:"equipmentId"`
+	Model               string            `json:"model"`
+	SerialNumber        string            `json:"serialNumber"`
+	OwnerOrg            string            `json:"ownerOrg"`
+	IsReferenceStandard bool              `json:"isReferenceStandard"`
+	Traceable           bool              `json:"traceable"`
+	Calibration         *TraceabilityNode `json:"calibration,omitempty" metadata:",optional"` // 设备的校准证书
+	Problem             string            `json:"problem,omitempty" metadata:",optional"`     // 溯源链中断的原因
+}
+
+// GetTraceabilityChain 从证书出发，沿设备→校准证书→设备逐级追溯，直到基准或最高计量标准
+func (s *SmartContract) GetTraceabilityChain(ctx contractapi.TransactionContextInterface, id string) (*TraceabilityNode, error) {
+	cert, err := s.ReadCertificate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := getTxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildTraceabilityNode(ctx, cert, now, map[string]bool{}), nil
+}
+
+// buildTraceabilityNode 构造证书节点，path记录当前路径上的证书以检测循环引用。
+// 上游校准证书属于其他机构，仅返回编号和状态等公开信息，不做读取权限检查。
+func (s *SmartContract) buildTraceabilityNode(ctx contractapi.TransactionContextInterface, cert *Certificate, now time.Time, path map[string]bool) *TraceabilityNode {
+	applyEffectiveStatus(cert, now)
+
+	node := &TraceabilityNode{
+		CertificateID: cert.ID,
+		CertificateNo: cert.CertificateNo,
+		Status:        cert.Status,
+		ValidUntil:    cert.ValidUntil,
+		Traceable:     len(cert.EquipmentIDs) > 0,
+	}
+
+	path[cert.ID] = true
+	defer delete(path, cert.ID)
+
+	for _, equipmentID := range cert.EquipmentIDs {
+		equipmentNode := s.buildEquipmentTraceNode(ctx, cert, equipmentID, now, path)
+		node.Equipment = append(node.Equipment, equipmentNode)
+		node.Traceable = node.Traceable && equipmentNode.Traceable
+	}
+
+	return node
+}
+
+// buildEquipmentTraceNode 构造设备节点并继续追溯其在证书测试日期有效的校准证书，
+// 与签发时的检查选取同一份校准证书，而非设备当前的校准证书
+func (s *SmartContract) buildEquipmentTraceNode(ctx contractapi.TransactionContextInterface, cert *Certificate, equipmentID string, now time.Time, path map[string]bool) *EquipmentTraceNode {
+	equipment, err := readEquipment(ctx, equipmentID)
+	if err != nil {
+		return &EquipmentTraceNode{EquipmentID: equipmentID, Problem: err.Error()}
+	}
+
+	node := &EquipmentTraceNode{
+		EquipmentID:         equipment.ID,
+		Model:               equipment.Model,
+		SerialNumber:        equipment.SerialNumber,
+		OwnerOrg:            equipment.OwnerOrg,
+		IsReferenceStandard: equipment.IsReferenceStandard,
+	}
+
+	if equipment.IsReferenceStandard {
+		node.Traceable = true
+		return node
+	}
+
+	testStart, testEnd, err := parseTestDate(cert.TestDate)
+	if err != nil {
+		node.Problem = err.Error()
+		return node
+	}
+
+	calibration, problem, err := s.calibrationForTest(ctx, equipment, testStart, testEnd)
+	switch {
+	case err != nil:
+		node.Problem = err.Error()
+		return node
+	case calibration == nil:
+		node.Problem = "equipment has no calibration certificate"
+		return node
+	case path[calibration.ID]:
+		node.Problem = fmt.Sprintf("circular reference to certificate %s", calibration.ID)
+		return node
+	}
+
+	node.Calibration = s.buildTraceabilityNode(ctx, calibration, now, path)
+	if problem != "" {
+		node.Problem = fmt.Sprintf("no calibration valid on %s: %s", cert.TestDate, problem)
+		return node
+	}
+	node.Traceable = node.Calibration.Traceable
+
+	return node
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// SubmitForReview 检验员将草稿证书提交核验
+func (s *SmartContract) SubmitForReview(ctx contractapi.TransactionContextInterface, id string) error {
+	identity, err := requireRole(ctx, "submit certificates for review", roleInspector)
+	if err != nil {
+		return err
+	}
+
+	cert, err := s.readCertificate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if cert.Status != statusDraft {
+		return fmt.Errorf("certificate %s is not in draft status", id)
+	}
+
+	// 记录提交时检验员的身份，检验员此后被注销也能执行核验的职责分离检查
+	inspector, err := readInspector(ctx, cert.Inspector)
+	if err != nil {
+		return err
+	}
+
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	cert.Status = statusSubmitted
+	cert.SubmittedBy = identity.Subject
+	cert.InspectorIdentity = inspector.Identity
+	cert.UpdatedAt = now
+
+	traceRecord := newTraceRecord(identity, now, "SUBMITTED", "Certificate submitted for review")
+	cert.TraceHistory = append(cert.TraceHistory, traceRecord)
+
+	if err := putCertificate(ctx, cert); err != nil {
+		return err
+	}
+
+	return emitCertificateEvent(ctx, eventCertificateSubmitted, cert, now)
+}
+
+// ReviewCertificate 核验员核验证书：通过则进入待批准状态，驳回则退回草稿
+func (s *SmartContract) ReviewCertificate(ctx contractapi.TransactionContextInterface, id string, approved bool, comments string) error {
+	identity, err := requireRole(ctx, "review certificates", roleReviewer)
+	if err != nil {
+		return err
+	}
+
+	cert, err := s.readCertificate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if cert.Status != statusSubmitted {
+		return fmt.Errorf("certificate %s is not in submitted status", id)
+	}
+
+	// 核验员不能是提交该证书的检验员，也不能是证书指定的检验员
+	if identity.Subject == cert.SubmittedBy {
+		return permissionDenied("reviewer %s cannot review a certificate they submitted", identity.Subject)
+	}
+	if cert.InspectorIdentity != "" && identity.Subject == cert.InspectorIdentity {
+		return permissionDenied("reviewer %s cannot review a certificate they are the inspector of", identity.Subject)
+	}
+
+	if !approved && comments == "" {
+		return fmt.Errorf("comments are required when rejecting certificate %s", id)
+	}
+
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	cert.ReviewedBy = identity.Subject
+	cert.ReviewComments = comments
+	cert.UpdatedAt = now
+
+	var traceRecord TraceRecord
+	if approved {
+		cert.Status = statusReviewed
+		traceRecord = newTraceRecord(identity, now, "REVIEWED", fmt.Sprintf("Certificate review passed. Comments: %s", comments))
+	} else {
+		cert.Status = statusDraft
+		cert.SubmittedBy = ""
+		traceRecord = newTraceRecord(identity, now, "REJECTED", fmt.Sprintf("Certificate review rejected. Comments: %s", comments))
+	}
+	cert.TraceHistory = append(cert.TraceHistory, traceRecord)
+
+	if err := putCertificate(ctx, cert); err != nil {
+		return err
+	}
+
+	return emitCertificateEvent(ctx, eventCertificateReviewed, cert, now)
+}
+
+// ApproveCertificate 批准人批准已核验的证书，批准后方可签发
+func (s *SmartContract) ApproveCertificate(ctx contractapi.TransactionContextInterface, id string) error {
+	identity, err := requireRole(ctx, "approve certificates", roleApprover)
+	if err != nil {
+		return err
+	}
+
+	cert, err := s.readCertificate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if cert.Status != statusReviewed {
+		return fmt.Errorf("certificate %s is not in reviewed status", id)
+	}
+
+	now, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	cert.Status = statusApproved
+	cert.ApprovedBy = identity.Subject
+	cert.UpdatedAt = now
+
+	traceRecord := newTraceRecord(identity, now, "APPROVED", "Certificate approved")
+	cert.TraceHistory = append(cert.TraceHistory, traceRecord)
+
+	if err := putCertificate(ctx, cert); err != nil {
+		return err
+	}
+
+	return emitCertificateEvent(ctx, eventCertificateApproved, cert, now)
+}
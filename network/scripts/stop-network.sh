@@ -37,6 +37,7 @@ stop_network() {
         sudo rm -rf channel-artifacts
         sudo rm -f mychannel.block
         sudo rm -f certificate.tar.gz
+        rm -rf wallet
     fi
     
     # 清理Docker资源
@@ -1,17 +1,56 @@
 #!/bin/bash
 
 API_BASE="http://localhost:8080/api/v1"
+SCRIPT_DIR=$(cd "$(dirname "$0")" && pwd)
+
+# 检验员INSP-001的身份，用于对证书内容哈希签名，由start-network.sh登记
+INSPECTOR_MSP="${SCRIPT_DIR}/../crypto-config/peerOrganizations/cert.example.com/users/inspector1@cert.example.com/msp"
+
+# 各用户的访问令牌，由start-network.sh生成。后端以调用者本人的身份提交交易，
+# 提交核验、核验和批准须由不同的人完成
+source "${SCRIPT_DIR}/../wallet/tokens.env"
+
+# api <令牌> <curl参数...> 以指定用户身份调用后端接口
+api() {
+    local token=$1
+    shift
+    curl -s -H "Authorization: Bearer ${token}" "$@"
+}
 
 echo "=== 计量证书区块链API测试 ==="
 
-# 1. 创建证书
-echo "1. 创建证书..."
-CERT_RESPONSE=$(curl -s -X POST ${API_BASE}/certificates \
+# 1. 登记客户并创建证书
+echo "1. 登记客户并创建证书..."
+CUSTOMER_RESPONSE=$(api ${INSPECTOR1_TOKEN} -X POST ${API_BASE}/customers \
+-H "Content-Type: application/json" \
+-d '{
+  "creditCode": "914403001922038216",
+  "name": "华为技术有限公司",
+  "aliases": ["华为"],
+  "contactMsp": "TestOrgMSP"
+}')
+
+CUSTOMER_ID=$(echo $CUSTOMER_RESPONSE | jq -r '.customerId')
+echo "客户登记成功，ID: $CUSTOMER_ID"
+
+CERT_RESPONSE=$(api ${INSPECTOR1_TOKEN} -X POST ${API_BASE}/certificates \
 -H "Content-Type: application/json" \
 -d '{
   "certificateNo": "CERT-2025-001",
-  "testUnit": "华为技术有限公司",
+  "customerId": "'"${CUSTOMER_ID}"'",
+  "item": {
+    "manufacturer": "Fluke",
+    "model": "8846A",
+    "serialNumber": "SN-8846A-0001",
+    "assetTag": "HW-EQ-1024",
+    "description": "六位半数字多用表"
+  },
   "testDate": "2025-01-15",
+  "environment": {
+    "temperature": {"value": 20.1, "unit": "℃", "uncertainty": 0.2},
+    "humidity": {"value": 45, "unit": "%RH", "uncertainty": 2},
+    "pressure": {"value": 101.3, "unit": "kPa", "uncertainty": 0.1}
+  },
   "testData": [
     {
       "parameter": "电压",
@@ -30,10 +69,9 @@ CERT_RESPONSE=$(curl -s -X POST ${API_BASE}/certificates \
       "equipment": "数字钳表"
     }
   ],
-  "inspectionOrg": "中国计量科学研究院",
-  "inspector": "张三",
-  "validUntil": "2026-01-15",
-  "createdBy": "system"
+  "inspectionOrg": "NIM",
+  "inspector": "INSP-001",
+  "validUntil": "2026-01-15"
 }')
 
 CERT_ID=$(echo $CERT_RESPONSE | jq -r '.certificateId')
@@ -41,30 +79,47 @@ echo "证书创建成功，ID: $CERT_ID"
 
 # 2. 获取证书详情
 echo -e "\n2. 获取证书详情..."
-curl -s -X GET ${API_BASE}/certificates/${CERT_ID} | jq .
+api ${INSPECTOR1_TOKEN} -X GET ${API_BASE}/certificates/${CERT_ID} | jq .
 
-# 3. 签发证书
-echo -e "\n3. 签发证书..."
-curl -s -X POST ${API_BASE}/certificates/${CERT_ID}/issue \
+# 3. 检验员提交核验，核验员核验，批准人批准并签发证书
+echo -e "\n3. 提交核验、核验、批准并签发证书..."
+api ${INSPECTOR1_TOKEN} -X POST ${API_BASE}/certificates/${CERT_ID}/submit | jq .
+api ${REVIEWER1_TOKEN} -X POST ${API_BASE}/certificates/${CERT_ID}/review \
 -H "Content-Type: application/json" \
 -d '{
-  "operator": "张三"
+  "approved": true,
+  "comments": "数据核验无误"
 }' | jq .
+api ${APPROVER1_TOKEN} -X POST ${API_BASE}/certificates/${CERT_ID}/approve | jq .
 
-# 4. 获取更新后的证书
-echo -e "\n4. 获取签发后的证书..."
-curl -s -X GET ${API_BASE}/certificates/${CERT_ID} | jq .
+# 检验员对证书内容哈希进行ECDSA签名后签发
+CERT_HASH=$(api ${INSPECTOR1_TOKEN} -X GET ${API_BASE}/certificates/${CERT_ID} | jq -r '.hash')
+SIGNATURE=$(echo -n ${CERT_HASH} | xxd -r -p | openssl pkeyutl -sign -inkey $(ls ${INSPECTOR_MSP}/keystore/*_sk) | base64 -w0)
+SIGNER_CERT=$(cat ${INSPECTOR_MSP}/signcerts/cert.pem)
+jq -n --arg signature "${SIGNATURE}" --arg signerCertificate "${SIGNER_CERT}" \
+  '{signature: $signature, signerCertificate: $signerCertificate}' | \
+api ${APPROVER1_TOKEN} -X POST ${API_BASE}/certificates/${CERT_ID}/issue \
+-H "Content-Type: application/json" \
+-d @- | jq .
+
+# 4. 送检单位获取签发后的证书
+echo -e "\n4. 送检单位获取签发后的证书..."
+api ${CUSTOMER1_TOKEN} -X GET ${API_BASE}/certificates/${CERT_ID} | jq .
 
 # 5. 查询所有证书
 echo -e "\n5. 查询所有证书..."
-curl -s -X GET ${API_BASE}/certificates | jq .
+api ${INSPECTOR1_TOKEN} -X GET ${API_BASE}/certificates | jq .
 
 # 6. 按状态查询证书
 echo -e "\n6. 按状态查询证书..."
-curl -s -X GET "${API_BASE}/certificates?status=issued" | jq .
+api ${INSPECTOR1_TOKEN} -X GET "${API_BASE}/certificates?status=issued" | jq .
 
 # 7. 获取证书历史
 echo -e "\n7. 获取证书历史..."
-curl -s -X GET ${API_BASE}/certificates/${CERT_ID}/history | jq .
+api ${INSPECTOR1_TOKEN} -X GET ${API_BASE}/certificates/${CERT_ID}/history | jq .
+
+# 8. 获取被校设备的校准历史
+echo -e "\n8. 获取被校设备的校准历史..."
+api ${INSPECTOR1_TOKEN} -X GET ${API_BASE}/instruments/SN-8846A-0001/certificates | jq .
 
 echo -e "\n=== API测试完成 ==="
\ No newline at end of file